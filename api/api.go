@@ -0,0 +1,13 @@
+package api
+
+import _ "embed"
+
+// OpenAPISchema is the OpenAPI document of the api, generated from the annotations of the sources
+//
+//go:embed openapi.yaml
+var OpenAPISchema string
+
+// OpenAPISchemaJSON is the JSON version of OpenAPISchema
+//
+//go:embed openapi.json
+var OpenAPISchemaJSON string
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/denouche/go-api-skeleton/handlers"
+	"github.com/denouche/go-api-skeleton/pkg/client/contracttest"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	parameterContractsDir = "dir"
+)
+
+var (
+	defaultContractsDir = "contracts"
+)
+
+var contractsDir string
+
+var contractsCmd = &cobra.Command{
+	Use:   "contracts",
+	Short: "Manage the contracts of the consumers of the api",
+}
+
+var contractsVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify that the api still fulfills the contracts recorded by its consumers",
+	Long: "Replay the interactions of each contract file of the directory against the api with the db in memory, " +
+		"and fail when a response does not match the expected one. The contracts are recorded by the consumers " +
+		"with the pkg/client/contracttest package.",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		utils.InitLogger(logrus.ErrorLevel.String(), defaultLogFormat)
+		if err := httputils.InitErrorFormat(defaultErrorFormat, defaultProblemTypeBaseURI); err != nil {
+			return err
+		}
+		if err := i18n.Init(defaultMessagesDir, defaultDefaultLanguage); err != nil {
+			return err
+		}
+
+		contracts, err := contracttest.LoadDir(contractsDir)
+		if err != nil {
+			return err
+		}
+
+		broken := 0
+		for _, contract := range contracts {
+			// each contract is replayed against a new api, its interactions depending only on the previous ones
+			hc := handlers.NewContext(&handlers.Config{
+				DBInMemory:                true,
+				IdempotencyKeyTTL:         defaultIdempotencyKeyTTL,
+				MaxRequestBodySize:        defaultMaxRequestBodySize,
+				BatchMaxItems:             defaultBatchMaxItems,
+				BatchMaxOperations:        defaultBatchMaxOperations,
+				OpenAPIValidation:         true,
+				OpenAPIResponseValidation: true,
+			})
+			err := contracttest.Verify(handlers.NewRouter(hc), contract)
+			if err != nil {
+				fmt.Println(err)
+				broken++
+				continue
+			}
+			fmt.Printf("contract of %s verified, %d interactions\n", contract.Consumer, len(contract.Interactions))
+		}
+		if broken > 0 {
+			return fmt.Errorf("%d of %d contracts broken", broken, len(contracts))
+		}
+		return nil
+	},
+}
+
+func init() {
+	contractsVerifyCmd.Flags().StringVar(&contractsDir, parameterContractsDir, defaultContractsDir, "Use this flag to set the directory of the contract files")
+
+	contractsCmd.AddCommand(contractsVerifyCmd)
+	rootCmd.AddCommand(contractsCmd)
+}
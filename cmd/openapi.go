@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/denouche/go-api-skeleton/utils/openapi"
+	"github.com/spf13/cobra"
+)
+
+const (
+	parameterOpenAPIDir       = "dir"
+	parameterOpenAPIExclude   = "exclude"
+	parameterOpenAPIInfoFile  = "info"
+	parameterOpenAPIOutputDir = "output-dir"
+)
+
+var (
+	defaultOpenAPIDir       = "."
+	defaultOpenAPIExclude   = []string{"vendor", "pkg"}
+	defaultOpenAPIInfoFile  = "info.yaml"
+	defaultOpenAPIOutputDir = "api"
+)
+
+var openAPIConfig = openapi.Config{}
+
+var openAPICmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Manage the openapi document of the api",
+}
+
+var openAPIGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate the openapi document from the @openapi:path and @openapi:schema annotations of the sources",
+	Long: "Generate the openapi document from the @openapi:path annotations of the handlers and the @openapi:schema " +
+		"annotations of the models, whose validate tags are given as constraints. The info file is merged in the document, " +
+		"written as " + openapi.FileNameYAML + " and " + openapi.FileNameJSON + " in the output directory, to be embedded in the api.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := openapi.Generate(openAPIConfig)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("openapi document generated in %s\n", openAPIConfig.OutputDir)
+		return nil
+	},
+}
+
+func init() {
+	openAPIGenerateCmd.Flags().StringVar(&openAPIConfig.Dir, parameterOpenAPIDir, defaultOpenAPIDir, "Use this flag to set the root directory of the annotated sources")
+	openAPIGenerateCmd.Flags().StringSliceVar(&openAPIConfig.Exclude, parameterOpenAPIExclude, defaultOpenAPIExclude, "Use this flag to set the names of the directories not parsed")
+	openAPIGenerateCmd.Flags().StringVar(&openAPIConfig.InfoFile, parameterOpenAPIInfoFile, defaultOpenAPIInfoFile, "Use this flag to set the file merged in the document, giving its info, servers, tags...")
+	openAPIGenerateCmd.Flags().StringVar(&openAPIConfig.OutputDir, parameterOpenAPIOutputDir, defaultOpenAPIOutputDir, "Use this flag to set the directory where the documents are written")
+
+	openAPICmd.AddCommand(openAPIGenerateCmd)
+	rootCmd.AddCommand(openAPICmd)
+}
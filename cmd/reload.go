@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/denouche/go-api-skeleton/storage/admission"
+	"github.com/denouche/go-api-skeleton/storage/validators"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// loadAndWatchFile reads file and gives its content to load, then does it again each time the file changes.
+// When the new content is not valid, load must keep the previous one.
+func loadAndWatchFile(file, description string, load func(v *viper.Viper) error) error {
+	v := viper.New()
+	v.SetConfigFile(file)
+
+	read := func() error {
+		err := v.ReadInConfig()
+		if err != nil {
+			return err
+		}
+		return load(v)
+	}
+
+	err := read()
+	if err != nil {
+		return err
+	}
+
+	v.OnConfigChange(func(e fsnotify.Event) {
+		err := read()
+		if err != nil {
+			utils.GetLogger().WithError(err).WithField("file", file).Errorf("error while reloading %s, keeping the previous ones", description)
+			return
+		}
+		utils.GetLogger().WithField("file", file).Infof("%s reloaded", description)
+	})
+	v.WatchConfig()
+	return nil
+}
+
+// initValidationRules loads the validation rules of the entities from file, and reloads them each time the file changes
+func initValidationRules(file string) error {
+	return loadAndWatchFile(file, "validation rules", func(v *viper.Viper) error {
+		rules := map[string][]validators.Rule{}
+		err := v.Unmarshal(&rules)
+		if err != nil {
+			return err
+		}
+		return validators.SetRules(rules)
+	})
+}
+
+// initAdmissionWebhooks loads the admission webhooks from file, under the webhooks key, and reloads them each time the file changes
+func initAdmissionWebhooks(file string) error {
+	return loadAndWatchFile(file, "admission webhooks", func(v *viper.Viper) error {
+		var webhooks []admission.Webhook
+		err := v.UnmarshalKey("webhooks", &webhooks)
+		if err != nil {
+			return err
+		}
+		return admission.SetWebhooks(webhooks)
+	})
+}
+
+// initLogLevels sets the levels of the loggers from the config, and sets them again on SIGHUP, reading the config file
+// again, the temporary levels set on the admin listener being canceled
+func initLogLevels() error {
+	// the root level is the one of the logger, the default one when the configured one is invalid
+	err := setLogLevels(utils.GetLogger().GetLevel().String(), config.LogComponentLevels)
+	if err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			if viper.ConfigFileUsed() != "" {
+				if err := viper.ReadInConfig(); err != nil {
+					utils.GetLogger().WithError(err).WithField("file", viper.ConfigFileUsed()).Error("error while reloading the log levels, keeping the previous ones")
+					continue
+				}
+			}
+			err := setLogLevels(viper.GetString(parameterLogLevel), viper.GetStringSlice(parameterLogComponentLevels))
+			if err != nil {
+				utils.GetLogger().WithError(err).Error("error while reloading the log levels, keeping the previous ones")
+				continue
+			}
+			utils.GetLogger().WithField("levels", utils.GetLogLevels()).Warn("log levels reloaded")
+		}
+	}()
+	return nil
+}
+
+func setLogLevels(level string, componentLevels []string) error {
+	levels, err := utils.ParseLogComponentLevels(componentLevels)
+	if err != nil {
+		return err
+	}
+	return utils.ResetLogLevels(level, levels)
+}
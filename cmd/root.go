@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/denouche/go-api-skeleton/handlers"
 	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
+	"github.com/denouche/go-api-skeleton/utils/tracing"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -18,23 +23,85 @@ var (
 )
 
 const (
-	parameterConfigurationFile    = "config"
-	parameterLogLevel             = "log-level"
-	parameterLogFormat            = "log-format"
-	parameterDBConnectionURI      = "db-connection-uri"
-	parameterDBInMemory           = "db-in-memory"             // DAO IN MEMORY
-	parameterDBInMemoryImportFile = "db-in-memory-import-file" // DAO IN MEMORY
-	parameterDBName               = "db-name"
-	parameterPort                 = "port"
+	parameterConfigurationFile         = "config"
+	parameterLogLevel                  = "log-level"
+	parameterLogFormat                 = "log-format"
+	parameterLogComponentLevels        = "log-component-levels"
+	parameterLogSamplingInitial        = "log-sampling-initial"
+	parameterLogSamplingThereafter     = "log-sampling-thereafter"
+	parameterErrorFormat               = "error-format"
+	parameterProblemTypeBaseURI        = "problem-type-base-uri"
+	parameterMessagesDir               = "messages-dir"
+	parameterDefaultLanguage           = "default-language"
+	parameterDBConnectionURI           = "db-connection-uri"
+	parameterDBInMemory                = "db-in-memory"             // DAO IN MEMORY
+	parameterDBInMemoryImportFile      = "db-in-memory-import-file" // DAO IN MEMORY
+	parameterDBName                    = "db-name"
+	parameterPort                      = "port"
+	parameterCORSAllowedOrigins        = "cors-allowed-origins"
+	parameterCORSAllowedMethods        = "cors-allowed-methods"
+	parameterCORSAllowedHeaders        = "cors-allowed-headers"
+	parameterCORSExposedHeaders        = "cors-exposed-headers"
+	parameterCORSMaxAge                = "cors-max-age"
+	parameterCORSAllowCredentials      = "cors-allow-credentials"
+	parameterIdempotencyKeyTTL         = "idempotency-key-ttl"
+	parameterMaxRequestBodySize        = "max-request-body-size"
+	parameterBatchMaxItems             = "batch-max-items"
+	parameterBatchMaxOperations        = "batch-max-operations"
+	parameterValidationRulesFile       = "validation-rules-file"
+	parameterAdmissionWebhooksFile     = "admission-webhooks-file"
+	parameterOpenAPIValidation         = "openapi-validation"
+	parameterOpenAPIResponseValidation = "openapi-response-validation"
+	parameterDocs                      = "docs"
+	parameterDocsTryItOut              = "docs-try-it-out"
+	parameterHealthCheckTimeout        = "health-check-timeout"
+	parameterShutdownDelay             = "shutdown-delay"
+	parameterShutdownTimeout           = "shutdown-timeout"
+	parameterMetrics                   = "metrics"
+	parameterAdminAddress              = "admin-address"
+	parameterTracingExporter           = "tracing-exporter"
+	parameterTracingOTLPEndpoint       = "tracing-otlp-endpoint"
+	parameterTracingSampleRatio        = "tracing-sample-ratio"
+	parameterTracingServiceName        = "tracing-service-name"
 )
 
 var (
-	defaultLogLevel             = logrus.WarnLevel.String()
-	defaultLogFormat            = utils.LogFormatText
-	defaultDBInMemoryImportFile = "" // DAO IN MEMORY
-	defaultDBConnectionURI      = ""
-	defaultDBName               = ""
-	defaultPort                 = 8080
+	defaultLogLevel              = logrus.WarnLevel.String()
+	defaultLogFormat             = utils.LogFormatText
+	defaultLogComponentLevels    = []string{}
+	defaultLogSamplingInitial    = 0
+	defaultLogSamplingThereafter = 0
+	defaultErrorFormat           = httputils.ErrorFormatLegacy
+	defaultProblemTypeBaseURI    = "urn:problem-type:"
+	defaultMessagesDir           = ""
+	defaultDefaultLanguage       = "en"
+	defaultDBInMemoryImportFile  = "" // DAO IN MEMORY
+	defaultDBConnectionURI       = ""
+	defaultDBName                = ""
+	defaultPort                  = 8080
+	defaultCORSAllowedOrigins    = []string{}
+	defaultCORSAllowedMethods    = []string{}
+	defaultCORSAllowedHeaders    = httputils.AllowedHeaders
+	defaultCORSExposedHeaders    = httputils.ExposedHeaders
+	defaultCORSMaxAge            = 10 * time.Minute
+	defaultIdempotencyKeyTTL     = 24 * time.Hour
+	defaultMaxRequestBodySize    = int64(10 << 20)
+	defaultBatchMaxItems         = 1000
+	defaultBatchMaxOperations    = 100
+	defaultValidationRulesFile   = ""
+	defaultAdmissionWebhooksFile = ""
+	defaultOpenAPIValidation     = true
+	defaultDocs                  = true
+	defaultDocsTryItOut          = true
+	defaultHealthCheckTimeout    = 2 * time.Second
+	defaultShutdownDelay         = 5 * time.Second
+	defaultShutdownTimeout       = 30 * time.Second
+	defaultMetrics               = true
+	defaultAdminAddress          = "127.0.0.1:8081"
+	defaultTracingExporter       = tracing.ExporterNone
+	defaultTracingOTLPEndpoint   = ""
+	defaultTracingSampleRatio    = 1.0
+	defaultTracingServiceName    = "go-api-skeleton"
 )
 
 var rootCmd = &cobra.Command{
@@ -45,21 +112,92 @@ var rootCmd = &cobra.Command{
 			WithField(parameterConfigurationFile, cfgFile).
 			WithField(parameterLogLevel, config.LogLevel).
 			WithField(parameterLogFormat, config.LogFormat).
+			WithField(parameterLogComponentLevels, config.LogComponentLevels).
+			WithField(parameterLogSamplingInitial, config.LogSamplingInitial).
+			WithField(parameterLogSamplingThereafter, config.LogSamplingThereafter).
+			WithField(parameterErrorFormat, config.ErrorFormat).
+			WithField(parameterProblemTypeBaseURI, config.ProblemTypeBaseURI).
+			WithField(parameterMessagesDir, config.MessagesDir).
+			WithField(parameterDefaultLanguage, config.DefaultLanguage).
 			WithField(parameterPort, config.Port).
 			WithField(parameterDBInMemory, config.DBInMemory).                     // DAO IN MEMORY
 			WithField(parameterDBInMemoryImportFile, config.DBInMemoryImportFile). // DAO IN MEMORY
 			WithField(parameterDBConnectionURI, config.DBConnectionURI).
 			WithField(parameterDBName, config.DBName).
+			WithField(parameterCORSAllowedOrigins, config.CORSAllowedOrigins).
+			WithField(parameterCORSAllowedMethods, config.CORSAllowedMethods).
+			WithField(parameterCORSAllowedHeaders, config.CORSAllowedHeaders).
+			WithField(parameterCORSExposedHeaders, config.CORSExposedHeaders).
+			WithField(parameterCORSMaxAge, config.CORSMaxAge).
+			WithField(parameterCORSAllowCredentials, config.CORSAllowCredentials).
+			WithField(parameterIdempotencyKeyTTL, config.IdempotencyKeyTTL).
+			WithField(parameterMaxRequestBodySize, config.MaxRequestBodySize).
+			WithField(parameterBatchMaxItems, config.BatchMaxItems).
+			WithField(parameterBatchMaxOperations, config.BatchMaxOperations).
+			WithField(parameterValidationRulesFile, config.ValidationRulesFile).
+			WithField(parameterAdmissionWebhooksFile, config.AdmissionWebhooksFile).
+			WithField(parameterOpenAPIValidation, config.OpenAPIValidation).
+			WithField(parameterOpenAPIResponseValidation, config.OpenAPIResponseValidation).
+			WithField(parameterDocs, config.Docs).
+			WithField(parameterDocsTryItOut, config.DocsTryItOut).
+			WithField(parameterHealthCheckTimeout, config.HealthCheckTimeout).
+			WithField(parameterShutdownDelay, config.ShutdownDelay).
+			WithField(parameterShutdownTimeout, config.ShutdownTimeout).
+			WithField(parameterMetrics, config.Metrics).
+			WithField(parameterAdminAddress, config.AdminAddress).
+			WithField(parameterTracingExporter, config.TracingExporter).
+			WithField(parameterTracingOTLPEndpoint, config.TracingOTLPEndpoint).
+			WithField(parameterTracingSampleRatio, config.TracingSampleRatio).
+			WithField(parameterTracingServiceName, config.TracingServiceName).
 			Warn("Configuration")
 
 		utils.InitLogger(config.LogLevel, config.LogFormat)
+		utils.InitLogSampling(config.LogSamplingInitial, config.LogSamplingThereafter)
+		if err := initLogLevels(); err != nil {
+			utils.GetLogger().WithError(err).Fatal("error while setting the log levels")
+		}
+		if err := httputils.InitErrorFormat(config.ErrorFormat, config.ProblemTypeBaseURI); err != nil {
+			utils.GetLogger().WithError(err).Fatal("error while setting the error format")
+		}
+		if err := i18n.Init(config.MessagesDir, config.DefaultLanguage); err != nil {
+			utils.GetLogger().WithError(err).Fatal("error while loading the messages files")
+		}
+		if config.ValidationRulesFile != "" {
+			if err := initValidationRules(config.ValidationRulesFile); err != nil {
+				utils.GetLogger().WithError(err).Fatal("error while loading the validation rules")
+			}
+		}
+		if config.AdmissionWebhooksFile != "" {
+			if err := initAdmissionWebhooks(config.AdmissionWebhooksFile); err != nil {
+				utils.GetLogger().WithError(err).Fatal("error while loading the admission webhooks")
+			}
+		}
+
+		shutdownTracing, err := tracing.Init(tracing.Config{
+			Exporter:       config.TracingExporter,
+			OTLPEndpoint:   config.TracingOTLPEndpoint,
+			SampleRatio:    config.TracingSampleRatio,
+			ServiceName:    config.TracingServiceName,
+			ServiceVersion: handlers.ApplicationVersion,
+		})
+		if err != nil {
+			utils.GetLogger().WithError(err).Fatal("error while initializing the tracing")
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				utils.GetLogger().WithError(err).Error("error while exporting the last spans")
+			}
+		}()
 
 		hc := handlers.NewContext(config)
 
 		router := handlers.NewRouter(hc)
-		err := router.Run(fmt.Sprintf(":%d", config.Port))
+		adminRouter := handlers.NewAdminRouter(hc, router)
+		err = serve(hc, router, adminRouter)
 		if err != nil {
-			utils.GetLogger().WithError(err).Error("error while starting app")
+			utils.GetLogger().WithError(err).Error("error while running app")
 		}
 	},
 }
@@ -82,6 +220,27 @@ func init() {
 	rootCmd.Flags().String(parameterLogFormat, defaultLogFormat, "Use this flag to set the logging format")
 	_ = viper.BindPFlag(parameterLogFormat, rootCmd.Flags().Lookup(parameterLogFormat))
 
+	rootCmd.Flags().StringSlice(parameterLogComponentLevels, defaultLogComponentLevels, "Use this flag to set the logging level of components, as component=level: http, dao or validators. The other components log at the level of --log-level. The levels are reloaded from the config file on SIGHUP, and can be changed on the admin listener")
+	_ = viper.BindPFlag(parameterLogComponentLevels, rootCmd.Flags().Lookup(parameterLogComponentLevels))
+
+	rootCmd.Flags().Int(parameterLogSamplingInitial, defaultLogSamplingInitial, "Use this flag to sample the noisy logs: each message is logged this number of times per second at most, then once every --log-sampling-thereafter times. The errors are never sampled. If 0, the logs are not sampled")
+	_ = viper.BindPFlag(parameterLogSamplingInitial, rootCmd.Flags().Lookup(parameterLogSamplingInitial))
+
+	rootCmd.Flags().Int(parameterLogSamplingThereafter, defaultLogSamplingThereafter, "Use this flag to log a sampled message once every this number of times after the first --log-sampling-initial ones of the second. If 0, they are all dropped")
+	_ = viper.BindPFlag(parameterLogSamplingThereafter, rootCmd.Flags().Lookup(parameterLogSamplingThereafter))
+
+	rootCmd.Flags().String(parameterErrorFormat, defaultErrorFormat, "Use this flag to set the default errors format: 'legacy', or 'problem' for RFC 7807 problem details. Clients can always ask for problem details with the 'Accept: application/problem+json' header")
+	_ = viper.BindPFlag(parameterErrorFormat, rootCmd.Flags().Lookup(parameterErrorFormat))
+
+	rootCmd.Flags().String(parameterProblemTypeBaseURI, defaultProblemTypeBaseURI, "Use this flag to set the base URI of the problem details types, the error type is appended to it")
+	_ = viper.BindPFlag(parameterProblemTypeBaseURI, rootCmd.Flags().Lookup(parameterProblemTypeBaseURI))
+
+	rootCmd.Flags().String(parameterMessagesDir, defaultMessagesDir, "Use this flag to set a directory of messages files, named after their language (fr.json, pt-BR.json), adding languages or overriding the built-in messages of the errors")
+	_ = viper.BindPFlag(parameterMessagesDir, rootCmd.Flags().Lookup(parameterMessagesDir))
+
+	rootCmd.Flags().String(parameterDefaultLanguage, defaultDefaultLanguage, "Use this flag to set the language of the messages when the Accept-Language header of the request does not match any supported language")
+	_ = viper.BindPFlag(parameterDefaultLanguage, rootCmd.Flags().Lookup(parameterDefaultLanguage))
+
 	rootCmd.Flags().Int(parameterPort, defaultPort, "Use this flag to set the listening port of the api")
 	_ = viper.BindPFlag(parameterPort, rootCmd.Flags().Lookup(parameterPort))
 
@@ -96,6 +255,81 @@ func init() {
 
 	rootCmd.Flags().String(parameterDBInMemoryImportFile, defaultDBInMemoryImportFile, "Use this flag to import a dataset in db in memory mode") // DAO IN MEMORY
 	_ = viper.BindPFlag(parameterDBInMemoryImportFile, rootCmd.Flags().Lookup(parameterDBInMemoryImportFile))                                    // DAO IN MEMORY
+
+	rootCmd.Flags().StringSlice(parameterCORSAllowedOrigins, defaultCORSAllowedOrigins, "Use this flag to set the origins allowed to make cross origin requests. An origin can be exact (https://foo.example.com), a wildcard pattern (https://*.example.com), a regular expression prefixed by 'regexp:', or '*' to allow any origin")
+	_ = viper.BindPFlag(parameterCORSAllowedOrigins, rootCmd.Flags().Lookup(parameterCORSAllowedOrigins))
+
+	rootCmd.Flags().StringSlice(parameterCORSAllowedMethods, defaultCORSAllowedMethods, "Use this flag to restrict the methods allowed in cross origin requests. If empty, all the methods of each route are allowed")
+	_ = viper.BindPFlag(parameterCORSAllowedMethods, rootCmd.Flags().Lookup(parameterCORSAllowedMethods))
+
+	rootCmd.Flags().StringSlice(parameterCORSAllowedHeaders, defaultCORSAllowedHeaders, "Use this flag to set the headers allowed in cross origin requests")
+	_ = viper.BindPFlag(parameterCORSAllowedHeaders, rootCmd.Flags().Lookup(parameterCORSAllowedHeaders))
+
+	rootCmd.Flags().StringSlice(parameterCORSExposedHeaders, defaultCORSExposedHeaders, "Use this flag to set the response headers exposed to cross origin requests")
+	_ = viper.BindPFlag(parameterCORSExposedHeaders, rootCmd.Flags().Lookup(parameterCORSExposedHeaders))
+
+	rootCmd.Flags().Duration(parameterCORSMaxAge, defaultCORSMaxAge, "Use this flag to set how long the preflight responses can be cached by the browsers")
+	_ = viper.BindPFlag(parameterCORSMaxAge, rootCmd.Flags().Lookup(parameterCORSMaxAge))
+
+	rootCmd.Flags().Bool(parameterCORSAllowCredentials, false, "Use this flag to allow credentials (cookies, authorization header) in cross origin requests")
+	_ = viper.BindPFlag(parameterCORSAllowCredentials, rootCmd.Flags().Lookup(parameterCORSAllowCredentials))
+
+	rootCmd.Flags().Duration(parameterIdempotencyKeyTTL, defaultIdempotencyKeyTTL, "Use this flag to set how long the responses to requests with an Idempotency-Key header are kept to be replayed")
+	_ = viper.BindPFlag(parameterIdempotencyKeyTTL, rootCmd.Flags().Lookup(parameterIdempotencyKeyTTL))
+
+	rootCmd.Flags().Int64(parameterMaxRequestBodySize, defaultMaxRequestBodySize, "Use this flag to set the maximum size in bytes of the request bodies, the larger ones are rejected with a request too large error")
+	_ = viper.BindPFlag(parameterMaxRequestBodySize, rootCmd.Flags().Lookup(parameterMaxRequestBodySize))
+
+	rootCmd.Flags().Int(parameterBatchMaxItems, defaultBatchMaxItems, "Use this flag to set the maximum number of items of the bulk requests, the larger ones are rejected with a request too large error")
+	_ = viper.BindPFlag(parameterBatchMaxItems, rootCmd.Flags().Lookup(parameterBatchMaxItems))
+
+	rootCmd.Flags().Int(parameterBatchMaxOperations, defaultBatchMaxOperations, "Use this flag to set the maximum number of operations of the batch requests, the larger ones are rejected with a request too large error")
+	_ = viper.BindPFlag(parameterBatchMaxOperations, rootCmd.Flags().Lookup(parameterBatchMaxOperations))
+
+	rootCmd.Flags().String(parameterValidationRulesFile, defaultValidationRulesFile, "Use this flag to set the file of the validation rules of the entities, written as CEL expressions. The file is reloaded when it changes")
+	_ = viper.BindPFlag(parameterValidationRulesFile, rootCmd.Flags().Lookup(parameterValidationRulesFile))
+
+	rootCmd.Flags().String(parameterAdmissionWebhooksFile, defaultAdmissionWebhooksFile, "Use this flag to set the file of the admission webhooks, called before the entities writes to admit, deny or mutate them. The file is reloaded when it changes")
+	_ = viper.BindPFlag(parameterAdmissionWebhooksFile, rootCmd.Flags().Lookup(parameterAdmissionWebhooksFile))
+
+	rootCmd.Flags().Bool(parameterOpenAPIValidation, defaultOpenAPIValidation, "Use this flag to validate the requests against the openapi document, the invalid ones are rejected with a data validation error")
+	_ = viper.BindPFlag(parameterOpenAPIValidation, rootCmd.Flags().Lookup(parameterOpenAPIValidation))
+
+	rootCmd.Flags().Bool(parameterOpenAPIResponseValidation, false, "Use this flag to validate the responses against the openapi document, the invalid ones are replaced by a server error. The responses are buffered, use it in tests only")
+	_ = viper.BindPFlag(parameterOpenAPIResponseValidation, rootCmd.Flags().Lookup(parameterOpenAPIResponseValidation))
+
+	rootCmd.Flags().Bool(parameterDocs, defaultDocs, "Use this flag to serve the interactive documentation of the api at /docs")
+	_ = viper.BindPFlag(parameterDocs, rootCmd.Flags().Lookup(parameterDocs))
+
+	rootCmd.Flags().Bool(parameterDocsTryItOut, defaultDocsTryItOut, "Use this flag to allow sending requests from the interactive documentation, with the methods allowed by the CORS configuration")
+	_ = viper.BindPFlag(parameterDocsTryItOut, rootCmd.Flags().Lookup(parameterDocsTryItOut))
+
+	rootCmd.Flags().Duration(parameterHealthCheckTimeout, defaultHealthCheckTimeout, "Use this flag to set the timeout of each check of the readiness endpoint, like the ping of the database")
+	_ = viper.BindPFlag(parameterHealthCheckTimeout, rootCmd.Flags().Lookup(parameterHealthCheckTimeout))
+
+	rootCmd.Flags().Duration(parameterShutdownDelay, defaultShutdownDelay, "Use this flag to set how long the api keeps serving requests after a SIGTERM or SIGINT, not ready anymore, for the load balancers to stop sending it requests")
+	_ = viper.BindPFlag(parameterShutdownDelay, rootCmd.Flags().Lookup(parameterShutdownDelay))
+
+	rootCmd.Flags().Duration(parameterShutdownTimeout, defaultShutdownTimeout, "Use this flag to set how long the api waits for the requests in progress to end during its shutdown")
+	_ = viper.BindPFlag(parameterShutdownTimeout, rootCmd.Flags().Lookup(parameterShutdownTimeout))
+
+	rootCmd.Flags().Bool(parameterMetrics, defaultMetrics, "Use this flag to serve the metrics of the api in the Prometheus text format at /metrics, on the admin listener")
+	_ = viper.BindPFlag(parameterMetrics, rootCmd.Flags().Lookup(parameterMetrics))
+
+	rootCmd.Flags().String(parameterAdminAddress, defaultAdminAddress, "Use this flag to set the address of the admin listener, serving the health, metrics, profiling, routes and config endpoints, and the export and import of the db in memory: a TCP address (127.0.0.1:8081, :8081) or a unix socket (unix:/run/api/admin.sock). These endpoints are not authenticated, the default address only accepts local connections. If empty, they are not served")
+	_ = viper.BindPFlag(parameterAdminAddress, rootCmd.Flags().Lookup(parameterAdminAddress))
+
+	rootCmd.Flags().String(parameterTracingExporter, defaultTracingExporter, "Use this flag to set the exporter of the OpenTelemetry spans of the requests and db calls: 'none', 'stdout', or 'otlp' to send them to an OTLP/HTTP collector. The W3C trace context of the requests is propagated in any case")
+	_ = viper.BindPFlag(parameterTracingExporter, rootCmd.Flags().Lookup(parameterTracingExporter))
+
+	rootCmd.Flags().String(parameterTracingOTLPEndpoint, defaultTracingOTLPEndpoint, "Use this flag to set the URL of the OTLP/HTTP collector, e.g. http://localhost:4318. If empty, the OTEL_EXPORTER_OTLP_ENDPOINT environment variable is used")
+	_ = viper.BindPFlag(parameterTracingOTLPEndpoint, rootCmd.Flags().Lookup(parameterTracingOTLPEndpoint))
+
+	rootCmd.Flags().Float64(parameterTracingSampleRatio, defaultTracingSampleRatio, "Use this flag to set the ratio of the traces sampled, between 0 and 1, when the request trace context does not decide it")
+	_ = viper.BindPFlag(parameterTracingSampleRatio, rootCmd.Flags().Lookup(parameterTracingSampleRatio))
+
+	rootCmd.Flags().String(parameterTracingServiceName, defaultTracingServiceName, "Use this flag to set the service name of the spans")
+	_ = viper.BindPFlag(parameterTracingServiceName, rootCmd.Flags().Lookup(parameterTracingServiceName))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -117,9 +351,41 @@ func initConfig() {
 
 	config.LogLevel = viper.GetString(parameterLogLevel)
 	config.LogFormat = viper.GetString(parameterLogFormat)
+	config.LogComponentLevels = viper.GetStringSlice(parameterLogComponentLevels)
+	config.LogSamplingInitial = viper.GetInt(parameterLogSamplingInitial)
+	config.LogSamplingThereafter = viper.GetInt(parameterLogSamplingThereafter)
+	config.ErrorFormat = viper.GetString(parameterErrorFormat)
+	config.ProblemTypeBaseURI = viper.GetString(parameterProblemTypeBaseURI)
+	config.MessagesDir = viper.GetString(parameterMessagesDir)
+	config.DefaultLanguage = viper.GetString(parameterDefaultLanguage)
 	config.Port = viper.GetInt(parameterPort)
 	config.DBConnectionURI = viper.GetString(parameterDBConnectionURI)
 	config.DBName = viper.GetString(parameterDBName)
 	config.DBInMemory = viper.GetBool(parameterDBInMemory)                       // DAO IN MEMORY
 	config.DBInMemoryImportFile = viper.GetString(parameterDBInMemoryImportFile) // DAO IN MEMORY
+	config.CORSAllowedOrigins = viper.GetStringSlice(parameterCORSAllowedOrigins)
+	config.CORSAllowedMethods = viper.GetStringSlice(parameterCORSAllowedMethods)
+	config.CORSAllowedHeaders = viper.GetStringSlice(parameterCORSAllowedHeaders)
+	config.CORSExposedHeaders = viper.GetStringSlice(parameterCORSExposedHeaders)
+	config.CORSMaxAge = viper.GetDuration(parameterCORSMaxAge)
+	config.CORSAllowCredentials = viper.GetBool(parameterCORSAllowCredentials)
+	config.IdempotencyKeyTTL = viper.GetDuration(parameterIdempotencyKeyTTL)
+	config.MaxRequestBodySize = viper.GetInt64(parameterMaxRequestBodySize)
+	config.BatchMaxItems = viper.GetInt(parameterBatchMaxItems)
+	config.BatchMaxOperations = viper.GetInt(parameterBatchMaxOperations)
+	config.ValidationRulesFile = viper.GetString(parameterValidationRulesFile)
+	config.AdmissionWebhooksFile = viper.GetString(parameterAdmissionWebhooksFile)
+	config.OpenAPIValidation = viper.GetBool(parameterOpenAPIValidation)
+	config.OpenAPIResponseValidation = viper.GetBool(parameterOpenAPIResponseValidation)
+	config.Docs = viper.GetBool(parameterDocs)
+	config.DocsTryItOut = viper.GetBool(parameterDocsTryItOut)
+	config.HealthCheckTimeout = viper.GetDuration(parameterHealthCheckTimeout)
+	config.ShutdownDelay = viper.GetDuration(parameterShutdownDelay)
+	config.ShutdownTimeout = viper.GetDuration(parameterShutdownTimeout)
+	config.Metrics = viper.GetBool(parameterMetrics)
+	config.AdminAddress = viper.GetString(parameterAdminAddress)
+	config.TracingExporter = viper.GetString(parameterTracingExporter)
+	config.TracingOTLPEndpoint = viper.GetString(parameterTracingOTLPEndpoint)
+	config.TracingSampleRatio = viper.GetFloat64(parameterTracingSampleRatio)
+	config.TracingServiceName = viper.GetString(parameterTracingServiceName)
 }
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/handlers"
+	"github.com/denouche/go-api-skeleton/utils"
+)
+
+const (
+	unixSocketPrefix = "unix:"
+	// adminSocketUmask creates the admin socket with the 0600 mode
+	adminSocketUmask = 0177
+)
+
+// serve listens on the port of the config with handler, and on the admin address with adminHandler, until a SIGTERM
+// or SIGINT. The api is ready once it listens. On the signal it is not ready anymore but keeps serving the requests
+// during the shutdown delay, then stops listening and waits for the requests in progress up to the shutdown timeout.
+// The admin listener is stopped last, for the readiness to be checked until the end. A second signal stops the api
+// immediately.
+func serve(hc *handlers.Context, handler, adminHandler http.Handler) error {
+	servers := []*http.Server{{Handler: handler}}
+	listeners := []net.Listener{}
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", config.Port))
+	if err != nil {
+		return err
+	}
+	listeners = append(listeners, listener)
+
+	if config.AdminAddress != "" {
+		adminListener, err := listenAdmin(config.AdminAddress)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		servers = append(servers, &http.Server{Handler: adminHandler})
+		listeners = append(listeners, adminListener)
+	}
+
+	errs := make(chan error, len(servers))
+	for i := range servers {
+		go func(server *http.Server, listener net.Listener) {
+			errs <- server.Serve(listener)
+		}(servers[i], listeners[i])
+	}
+	hc.SetReady(true)
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-errs:
+		closeServers(servers)
+		return err
+	case sig := <-signals:
+		utils.GetLogger().WithField("signal", sig.String()).Warn("shutting down")
+	}
+	hc.SetReady(false)
+
+	select {
+	case <-time.After(config.ShutdownDelay):
+	case <-signals:
+		closeServers(servers)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for _, server := range servers {
+		err = server.Shutdown(ctx)
+		if err != nil {
+			// the requests still in progress are interrupted
+			closeServers(servers)
+			return err
+		}
+	}
+	for range servers {
+		if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+	return nil
+}
+
+// listenAdmin listens on a TCP address, or on a unix socket when the address is prefixed by unix:
+func listenAdmin(address string) (net.Listener, error) {
+	if !strings.HasPrefix(address, unixSocketPrefix) {
+		return net.Listen("tcp", address)
+	}
+	path := strings.TrimPrefix(address, unixSocketPrefix)
+	// the socket file of a previous run prevents listening, the other files are not to be removed
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s already exists and is not a unix socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	// only the user of the api can connect to the admin endpoints: the socket is created with this mode, not changed
+	// once created, to not let the other users connect in the meantime
+	umask := setUmask(adminSocketUmask)
+	defer setUmask(umask)
+	return net.Listen("unix", path)
+}
+
+func closeServers(servers []*http.Server) {
+	for _, server := range servers {
+		_ = server.Close()
+	}
+}
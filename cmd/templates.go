@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/pkg/client"
+	"github.com/denouche/go-api-skeleton/pkg/client/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	parameterServer   = "server"
+	parameterToken    = "token"
+	parameterUsername = "username"
+	parameterPassword = "password"
+	parameterTimeout  = "timeout"
+	parameterOutput   = "output"
+	parameterFile     = "file"
+	parameterIfMatch  = "if-match"
+
+	// the connection parameters are prefixed in the config file and environment variables (API_TOKEN),
+	// not to read variables like USERNAME set by the shell
+	configPrefixAPI = "api-"
+
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+
+	// fileStdin is the file name reading the standard input
+	fileStdin = "-"
+)
+
+var (
+	defaultServer  = "http://localhost:8080"
+	defaultTimeout = 30 * time.Second
+	defaultOutput  = outputTable
+	defaultFile    = fileStdin
+)
+
+var (
+	templatesTimeout time.Duration
+	templatesOutput  string
+	templatesFile    string
+	templatesIfMatch string
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Operate the templates of a running api",
+	Long: "Operate the templates of a running api, given by --server. The requests are authenticated with --token, " +
+		"or --username and --password. These flags can also be set in the config file, or as environment variables " +
+		"prefixed by API_ (API_SERVER, API_TOKEN...).",
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the templates",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := newCommandContext()
+		defer cancel()
+		templates, err := newClient().ListTemplates(ctx)
+		if err != nil {
+			return err
+		}
+		return printTemplates(cmd.OutOrStdout(), templates)
+	},
+}
+
+var templatesGetCmd = &cobra.Command{
+	Use:   "get <id>",
+	Short: "Get a template",
+	Long: "Get a template. Its ETag is written on the standard error, to be given to the update and delete commands " +
+		"with --if-match, to make sure the template is not modified in the meantime.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := newCommandContext()
+		defer cancel()
+		template, etag, err := newClient().GetTemplateWithETag(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "ETag: %s\n", etag)
+		return printTemplates(cmd.OutOrStdout(), template)
+	},
+}
+
+var templatesCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a template from a JSON or YAML file, or the standard input",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data := model.TemplateEditable{}
+		err := readBody(cmd.InOrStdin(), templatesFile, &data)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := newCommandContext()
+		defer cancel()
+		template, err := newClient().CreateTemplate(ctx, data)
+		if err != nil {
+			return err
+		}
+		return printTemplates(cmd.OutOrStdout(), template)
+	},
+}
+
+var templatesUpdateCmd = &cobra.Command{
+	Use:   "update <id>",
+	Short: "Replace a template by a JSON or YAML file, or the standard input",
+	Long: "Replace a template by a JSON or YAML file, or the standard input. The update is conditioned by --if-match, " +
+		"the ETag given by the get command: it fails if the template is modified by someone else in the meantime. " +
+		"Without --if-match, the update replaces the current version of the template.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data := model.TemplateEditable{}
+		err := readBody(cmd.InOrStdin(), templatesFile, &data)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := newCommandContext()
+		defer cancel()
+		c := newClient()
+		etag, err := getIfMatch(ctx, c, args[0])
+		if err != nil {
+			return err
+		}
+		template, err := c.UpdateTemplateIfMatch(ctx, args[0], etag, data)
+		if err != nil {
+			return err
+		}
+		return printTemplates(cmd.OutOrStdout(), template)
+	},
+}
+
+var templatesDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a template",
+	Long: "Delete a template. The deletion is conditioned by --if-match, the ETag given by the get command: it fails " +
+		"if the template is modified by someone else in the meantime. Without --if-match, the deletion deletes the " +
+		"current version of the template.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := newCommandContext()
+		defer cancel()
+		c := newClient()
+		etag, err := getIfMatch(ctx, c, args[0])
+		if err != nil {
+			return err
+		}
+		err = c.DeleteTemplateIfMatch(ctx, args[0], etag)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "template %s deleted\n", args[0])
+		return nil
+	},
+}
+
+func newClient() *client.Client {
+	options := []client.Option{
+		client.WithHTTPClient(&http.Client{Timeout: templatesTimeout}),
+	}
+	if token := viper.GetString(configPrefixAPI + parameterToken); token != "" {
+		options = append(options, client.WithBearerToken(token))
+	} else if username := viper.GetString(configPrefixAPI + parameterUsername); username != "" {
+		options = append(options, client.WithBasicAuth(username, viper.GetString(configPrefixAPI+parameterPassword)))
+	}
+	return client.New(viper.GetString(configPrefixAPI+parameterServer), options...)
+}
+
+// getIfMatch returns the ETag conditioning the write of the template: --if-match, or the ETag of its current version
+func getIfMatch(ctx context.Context, c *client.Client, id string) (string, error) {
+	if templatesIfMatch != "" {
+		return templatesIfMatch, nil
+	}
+	_, etag, err := c.GetTemplateWithETag(ctx, id)
+	return etag, err
+}
+
+func newCommandContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), templatesTimeout)
+}
+
+// readBody decodes the JSON or YAML content of file, or of stdin when file is "-", in v
+func readBody(stdin io.Reader, file string, v interface{}) error {
+	var content []byte
+	var err error
+	if file == fileStdin {
+		content, err = ioutil.ReadAll(stdin)
+	} else {
+		content, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+
+	// YAML being a superset of JSON, both are read as YAML, then given to the JSON decoder to use the json tags
+	var data interface{}
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		return fmt.Errorf("invalid body: %w", err)
+	}
+	if data == nil {
+		return fmt.Errorf("invalid body: empty")
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("invalid body: %w", err)
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(b)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid body: %w", err)
+	}
+	return nil
+}
+
+// printTemplates writes a template or a list of templates in the output format
+func printTemplates(w io.Writer, data interface{}) error {
+	switch output := templatesOutput; output {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case outputYAML:
+		// the data are converted through JSON to keep the names of the json tags
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		if err := yaml.Unmarshal(b, &generic); err != nil {
+			return err
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		defer encoder.Close()
+		return encoder.Encode(generic)
+	case outputTable:
+		var templates []*model.Template
+		switch d := data.(type) {
+		case *model.Template:
+			templates = []*model.Template{d}
+		case []*model.Template:
+			templates = d
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tCREATED AT\tUPDATED AT")
+		for _, t := range templates {
+			updatedAt := ""
+			if t.UpdatedAt != nil {
+				updatedAt = t.UpdatedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", t.ID, t.Name, t.CreatedAt.Format(time.RFC3339), updatedAt)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q, expected %s, %s or %s", output, outputTable, outputJSON, outputYAML)
+	}
+}
+
+func init() {
+	templatesCmd.PersistentFlags().String(parameterServer, defaultServer, "Use this flag to set the URL of the api")
+	_ = viper.BindPFlag(configPrefixAPI+parameterServer, templatesCmd.PersistentFlags().Lookup(parameterServer))
+
+	templatesCmd.PersistentFlags().String(parameterToken, "", "Use this flag to authenticate the requests with a bearer token")
+	_ = viper.BindPFlag(configPrefixAPI+parameterToken, templatesCmd.PersistentFlags().Lookup(parameterToken))
+
+	templatesCmd.PersistentFlags().String(parameterUsername, "", "Use this flag to authenticate the requests with a basic authentication, with --password")
+	_ = viper.BindPFlag(configPrefixAPI+parameterUsername, templatesCmd.PersistentFlags().Lookup(parameterUsername))
+
+	templatesCmd.PersistentFlags().String(parameterPassword, "", "Use this flag to set the password of the basic authentication")
+	_ = viper.BindPFlag(configPrefixAPI+parameterPassword, templatesCmd.PersistentFlags().Lookup(parameterPassword))
+
+	templatesCmd.PersistentFlags().DurationVar(&templatesTimeout, parameterTimeout, defaultTimeout, "Use this flag to set the timeout of the commands, retries included")
+	templatesCmd.PersistentFlags().StringVarP(&templatesOutput, parameterOutput, "o", defaultOutput, "Use this flag to set the output format: table, json or yaml")
+
+	for _, cmd := range []*cobra.Command{templatesCreateCmd, templatesUpdateCmd} {
+		cmd.Flags().StringVarP(&templatesFile, parameterFile, "f", defaultFile, "Use this flag to set the JSON or YAML file of the template, '-' for the standard input")
+	}
+
+	for _, cmd := range []*cobra.Command{templatesUpdateCmd, templatesDeleteCmd} {
+		cmd.Flags().StringVar(&templatesIfMatch, parameterIfMatch, "", "Use this flag to set the ETag of the version of the template, given by the get command, instead of the ETag of its current version")
+	}
+
+	for _, cmd := range []*cobra.Command{templatesListCmd, templatesGetCmd, templatesCreateCmd, templatesUpdateCmd, templatesDeleteCmd} {
+		// the errors of the api are not usage errors
+		cmd.SilenceUsage = true
+		templatesCmd.AddCommand(cmd)
+	}
+	rootCmd.AddCommand(templatesCmd)
+}
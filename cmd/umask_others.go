@@ -0,0 +1,8 @@
+//go:build !unix
+
+package cmd
+
+// setUmask does nothing on the systems without file mode creation mask
+func setUmask(mask int) int {
+	return 0
+}
@@ -0,0 +1,10 @@
+//go:build unix
+
+package cmd
+
+import "syscall"
+
+// setUmask sets the file mode creation mask of the process, and returns the previous one
+func setUmask(mask int) int {
+	return syscall.Umask(mask)
+}
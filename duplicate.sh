@@ -143,6 +143,10 @@ createOneEntity()
         ${SED_CMD} -i -r "s/template/${ENTITY_NAME}/g" storage/dao/fake/database_fake_${ENTITY_NAME}.go
         ${SED_CMD} -i -r "s/Template/${ENTITY_NAME_UP}/g" storage/dao/fake/database_fake_${ENTITY_NAME}.go
 
+        cp storage/dao/tracing/database_tracing_template.go storage/dao/tracing/database_tracing_${ENTITY_NAME}.go
+        ${SED_CMD} -i -r "s/template/${ENTITY_NAME}/g" storage/dao/tracing/database_tracing_${ENTITY_NAME}.go
+        ${SED_CMD} -i -r "s/Template/${ENTITY_NAME_UP}/g" storage/dao/tracing/database_tracing_${ENTITY_NAME}.go
+
         cp storage/model/template.go storage/model/${ENTITY_NAME}.go
         ${SED_CMD} -i -r "s/Template/${ENTITY_NAME_UP}/g" storage/model/${ENTITY_NAME}.go
 
@@ -153,6 +157,8 @@ createOneEntity()
         ${SED_CMD} -i -r "/\/\/ Template export/{p;s/Template/${ENTITY_NAME_UP}/g}" storage/dao/fake/database_fake.go
 
         ${SED_CMD} -i -r "/\/\/ Template index/{p;s/Template/${ENTITY_NAME_UP}/g}" storage/dao/mongodb/database_mongodb.go
+
+        ${SED_CMD} -i -r "/\/\/ Template lookup/{p;s/Template/${ENTITY_NAME_UP}/g}" storage/dao/fake/database_fake_lookup.go storage/dao/postgresql/database_postgresql_lookup.go storage/dao/mongodb/database_mongodb_lookup.go
     fi
 }
 
@@ -172,6 +178,7 @@ clean()
         ${SED_CMD} -i -r "/\/\/ start: template dao funcs/{:next;N;/\/\/ end: template dao funcs/{bend};bnext;:end;d}" storage/dao/database.go
         ${SED_CMD} -i -r "/\/\/ Template export/d" storage/dao/fake/database_fake.go
         ${SED_CMD} -i -r "/\/\/ Template index/d" storage/dao/mongodb/database_mongodb.go
+        ${SED_CMD} -i -r "/\/\/ Template lookup/d" storage/dao/fake/database_fake_lookup.go storage/dao/postgresql/database_postgresql_lookup.go storage/dao/mongodb/database_mongodb_lookup.go
 
         find . -iname '*template*' -exec rm {} \;
     fi
@@ -191,7 +198,7 @@ clean()
 
     if [[ ${DAO_IN_MEMORY} -eq 0 ]]
     then
-        ${SED_CMD} -i -r '/\/\/ DAO IN MEMORY/d' handlers/handler.go cmd/root.go
+        ${SED_CMD} -i -r '/\/\/ DAO IN MEMORY/d' handlers/handler.go handlers/admin_handler.go cmd/root.go
         ${SED_CMD} -i -r '/(start-offline|db-in-memory)/d' Makefile
         rm -rf ./storage/dao/fake
     fi
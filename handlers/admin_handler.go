@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/middlewares"
+	dbFake "github.com/denouche/go-api-skeleton/storage/dao/fake" // DAO IN MEMORY
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/gin-gonic/gin"
+)
+
+const redactedValue = "REDACTED"
+
+// RouteInfo describes a route of a router
+type RouteInfo struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"`
+}
+
+// LogLevelUpdate is the new level of a logger, temporary when a duration is given, e.g. 10m
+type LogLevelUpdate struct {
+	Level    string `json:"level" binding:"required"`
+	Duration string `json:"duration"`
+}
+
+// NewAdminRouter returns the router of the operational endpoints: health, metrics, profiling, routes, config and log levels,
+// served on the admin listener, apart from the business endpoints of the public router
+func NewAdminRouter(hc *Context, public *gin.Engine) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+
+	router.Use(gin.Recovery())
+	router.Use(middlewares.GetLoggerMiddleware())
+	router.Use(middlewares.GetLanguageMiddleware())
+	router.Use(middlewares.GetErrorMiddleware())
+
+	router.Handle(http.MethodGet, "/_health", hc.GetHealth)
+	router.Handle(http.MethodHead, "/_health", hc.GetHealth)
+	router.Handle(http.MethodGet, "/_health/live", hc.GetHealthLive)
+	router.Handle(http.MethodHead, "/_health/live", hc.GetHealthLive)
+	router.Handle(http.MethodGet, "/_health/ready", hc.GetHealthReady)
+	router.Handle(http.MethodHead, "/_health/ready", hc.GetHealthReady)
+
+	if hc.metricsHandler != nil {
+		router.Handle(http.MethodGet, "/metrics", hc.GetMetrics)
+		router.Handle(http.MethodHead, "/metrics", hc.GetMetrics)
+	}
+
+	router.Handle(http.MethodGet, "/debug/pprof/*profile", hc.GetProfile)
+	router.Handle(http.MethodPost, "/debug/pprof/*profile", hc.GetProfile)
+
+	router.Handle(http.MethodGet, "/routes", hc.GetRoutes(public, router))
+	router.Handle(http.MethodGet, "/config", hc.GetConfig)
+
+	router.Handle(http.MethodGet, "/log-levels", hc.GetLogLevels)
+	router.Handle(http.MethodPut, "/log-levels/:component", hc.UpdateLogLevel)
+
+	if dbInMemory, ok := hc.db.(*dbFake.DatabaseFake); ok { // DAO IN MEMORY
+		// db in memory mode, add export and import endpoints // DAO IN MEMORY
+		router.Handle(http.MethodGet, "/export", func(c *gin.Context) { // DAO IN MEMORY
+			httputils.JSON(c.Writer, http.StatusOK, dbInMemory.Export()) // DAO IN MEMORY
+		}) // DAO IN MEMORY
+		router.Handle(http.MethodPost, "/import", func(c *gin.Context) { // DAO IN MEMORY
+			export := &dbFake.Export{}                       // DAO IN MEMORY
+			if err := c.ShouldBindJSON(export); err != nil { // DAO IN MEMORY
+				httputils.JSONError(c, model.ErrBadRequestFormat) // DAO IN MEMORY
+				return                                            // DAO IN MEMORY
+			} // DAO IN MEMORY
+			dbInMemory.Import(export)                  // DAO IN MEMORY
+			c.Writer.WriteHeader(http.StatusNoContent) // DAO IN MEMORY
+		}) // DAO IN MEMORY
+	} // DAO IN MEMORY
+
+	return router
+}
+
+// GetProfile serves the profiles of net/http/pprof, e.g. /debug/pprof/heap or /debug/pprof/profile?seconds=30
+func (hc *Context) GetProfile(c *gin.Context) {
+	switch strings.TrimPrefix(c.Param("profile"), "/") {
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		// the index also serves the named profiles: heap, goroutine, allocs...
+		pprof.Index(c.Writer, c.Request)
+	}
+}
+
+// GetRoutes lists the routes of the public and admin routers
+func (hc *Context) GetRoutes(public, admin *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		httputils.JSON(c.Writer, http.StatusOK, map[string][]RouteInfo{
+			"public": newRouteInfos(public.Routes()),
+			"admin":  newRouteInfos(admin.Routes()),
+		})
+	}
+}
+
+func newRouteInfos(routes gin.RoutesInfo) []RouteInfo {
+	result := make([]RouteInfo, 0, len(routes))
+	for _, r := range routes {
+		result = append(result, RouteInfo{Method: r.Method, Path: r.Path, Handler: r.Handler})
+	}
+	return result
+}
+
+// GetConfig sends the configuration of the api, its credentials redacted
+func (hc *Context) GetConfig(c *gin.Context) {
+	httputils.JSON(c.Writer, http.StatusOK, hc.config)
+}
+
+// GetLogLevels sends the levels of the root and components loggers
+func (hc *Context) GetLogLevels(c *gin.Context) {
+	httputils.JSON(c.Writer, http.StatusOK, utils.GetLogLevels())
+}
+
+// UpdateLogLevel sets the level of a logger until the api stops or the levels are reloaded on SIGHUP,
+// or during the given duration only
+func (hc *Context) UpdateLogLevel(c *gin.Context) {
+	component := c.Param("component")
+	if !isLoggerComponent(component) {
+		httputils.JSONError(c, model.ErrNotFound)
+		return
+	}
+
+	update := &LogLevelUpdate{}
+	if err := c.ShouldBindJSON(update); err != nil {
+		httputils.JSONError(c, model.ErrBadRequestFormat)
+		return
+	}
+	var duration time.Duration
+	if update.Duration != "" {
+		var err error
+		duration, err = time.ParseDuration(update.Duration)
+		if err != nil || duration <= 0 {
+			httputils.JSONErrorWithMessage(c, model.ErrBadRequestFormat, "the duration must be positive, e.g. 10m")
+			return
+		}
+	}
+
+	if err := utils.SetLogLevel(component, update.Level, duration); err != nil {
+		httputils.JSONErrorWithMessage(c, model.ErrBadRequestFormat, err.Error())
+		return
+	}
+	utils.GetLoggerFromCtx(c).
+		WithField("component", component).
+		WithField("logLevel", update.Level).
+		WithField("duration", duration.String()).
+		Warn("log level changed")
+	httputils.JSON(c.Writer, http.StatusOK, utils.GetLogLevels())
+}
+
+func isLoggerComponent(component string) bool {
+	for _, level := range utils.GetLogLevels() {
+		if level.Component == component {
+			return true
+		}
+	}
+	return false
+}
+
+// redactConfig returns a copy of config without the credentials of its URLs
+func redactConfig(config Config) Config {
+	config.DBConnectionURI = redactURL(config.DBConnectionURI)
+	config.TracingOTLPEndpoint = redactURL(config.TracingOTLPEndpoint)
+	return config
+}
+
+func redactURL(rawURL string) string {
+	if rawURL == "" {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// not a URL that can be partially redacted
+		return redactedValue
+	}
+	if u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), redactedValue)
+		}
+	}
+	query := u.Query()
+	for k := range query {
+		if strings.Contains(strings.ToLower(k), "password") {
+			query.Set(k, redactedValue)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/denouche/go-api-skeleton/storage/admission"
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/storage/validators"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/gin-gonic/gin"
+)
+
+// admit submits a write of entity to the admission webhooks. object points to the proposed data, nil on deletion,
+// and oldObject is the current entity, nil on creation. The object is updated with the mutations of the webhooks,
+// and validated again when mutated. It returns the error to send when the write is not admitted.
+func (hc *Context) admit(c *gin.Context, validationCtx context.Context, operation, entity string, object, oldObject interface{}) *model.APIError {
+	if !admission.HasWebhooks(entity, operation) {
+		return nil
+	}
+
+	request := model.AdmissionRequest{
+		UID:       c.GetString(utils.ContextKeyCorrelationID),
+		Entity:    entity,
+		Operation: operation,
+	}
+	var err error
+	if object != nil {
+		request.Object, err = json.Marshal(object)
+		if err != nil {
+			return admissionInternalError(c, err)
+		}
+	}
+	if oldObject != nil {
+		request.OldObject, err = json.Marshal(oldObject)
+		if err != nil {
+			return admissionInternalError(c, err)
+		}
+	}
+
+	result, err := admission.Review(c.Request.Context(), request)
+	var webhookErr *admission.WebhookError
+	if errors.As(err, &webhookErr) {
+		utils.GetLoggerFromCtx(c).WithError(err).Error("admission webhook failed")
+		apiErr := model.ErrAdmissionWebhookFailed
+		return &apiErr
+	} else if err != nil {
+		return admissionInternalError(c, err)
+	}
+
+	if !result.Allowed {
+		apiErr := model.ErrAdmissionDenied
+		if result.Message != "" {
+			apiErr.Description = result.Message
+		}
+		return &apiErr
+	}
+
+	if object == nil || string(result.Object) == string(request.Object) {
+		return nil
+	}
+
+	// the webhooks mutated the object, it must still be valid
+	value := reflect.ValueOf(object).Elem()
+	value.Set(reflect.Zero(value.Type()))
+	err = json.Unmarshal(result.Object, object)
+	if err != nil {
+		utils.GetLoggerFromCtx(c).WithError(err).Error("admission webhook mutation does not match the entity")
+		apiErr := model.ErrAdmissionWebhookFailed
+		return &apiErr
+	}
+	err = hc.validator.StructCtx(validationCtx, object)
+	if errDB := validators.GetValidationContextError(validationCtx); errDB != nil {
+		utils.GetLoggerFromCtx(c).WithError(errDB).Error("error while validating the entity mutated by admission webhooks")
+		apiErr := httputils.NewAPIErrorFromError(errDB, "")
+		return &apiErr
+	}
+	if err != nil {
+		apiErr := validators.NewDataValidationAPIError(err)
+		return &apiErr
+	}
+	return validators.ValidateRules(entity, object)
+}
+
+func admissionInternalError(c *gin.Context, err error) *model.APIError {
+	utils.GetLoggerFromCtx(c).WithError(err).Error("error while submitting write to admission webhooks")
+	apiErr := model.ErrInternalServer
+	return &apiErr
+}
@@ -0,0 +1,129 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/handlers"
+	"github.com/denouche/go-api-skeleton/storage/admission"
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
+)
+
+// newAdmissionWebhook starts a webhook answering with response, and returns its url
+func newAdmissionWebhook(t *testing.T, response model.AdmissionResponse) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestAdmission(t *testing.T) {
+	utils.InitLogger("error", utils.LogFormatText)
+	if err := i18n.Init("", "en"); err != nil {
+		t.Fatal(err)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(failing.Close)
+
+	tests := []struct {
+		name         string
+		webhook      admission.Webhook
+		expectedCode int
+		expectedType string
+		expectedBody string
+	}{
+		{
+			name:         "allowed",
+			webhook:      admission.Webhook{URL: newAdmissionWebhook(t, model.AdmissionResponse{Allowed: true})},
+			expectedCode: http.StatusCreated,
+			expectedBody: `"name":"foo"`,
+		},
+		{
+			name: "denied with a message",
+			webhook: admission.Webhook{URL: newAdmissionWebhook(t, model.AdmissionResponse{
+				Message: "names starting with foo are reserved",
+			})},
+			expectedCode: http.StatusForbidden,
+			expectedType: model.ErrAdmissionDenied.Type,
+			expectedBody: "names starting with foo are reserved",
+		},
+		{
+			name: "mutated",
+			webhook: admission.Webhook{URL: newAdmissionWebhook(t, model.AdmissionResponse{
+				Allowed: true,
+				Patch:   []model.PatchOperation{{Op: "replace", Path: "/name", Value: "bar"}},
+			})},
+			expectedCode: http.StatusCreated,
+			expectedBody: `"name":"bar"`,
+		},
+		{
+			name: "mutated into an invalid template",
+			webhook: admission.Webhook{URL: newAdmissionWebhook(t, model.AdmissionResponse{
+				Allowed: true,
+				Patch:   []model.PatchOperation{{Op: "replace", Path: "/name", Value: ""}},
+			})},
+			expectedCode: http.StatusBadRequest,
+			expectedType: model.ErrDataValidation.Type,
+		},
+		{
+			name:         "failing webhook with Fail policy",
+			webhook:      admission.Webhook{URL: failing.URL, FailurePolicy: admission.FailurePolicyFail},
+			expectedCode: http.StatusBadGateway,
+			expectedType: model.ErrAdmissionWebhookFailed.Type,
+		},
+		{
+			name:         "failing webhook with Ignore policy",
+			webhook:      admission.Webhook{URL: failing.URL, FailurePolicy: admission.FailurePolicyIgnore},
+			expectedCode: http.StatusCreated,
+			expectedBody: `"name":"foo"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.webhook.Name = "test"
+			if err := admission.SetWebhooks([]admission.Webhook{tt.webhook}); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				_ = admission.SetWebhooks(nil)
+			})
+			router := handlers.NewRouter(handlers.NewContext(&handlers.Config{
+				DBInMemory:        true,
+				IdempotencyKeyTTL: 24 * time.Hour,
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/templates", strings.NewReader(`{"name":"foo"}`))
+			r.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, r)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body)
+			}
+			if tt.expectedType != "" {
+				apiErr := model.APIError{}
+				if err := json.Unmarshal(w.Body.Bytes(), &apiErr); err != nil {
+					t.Fatal(err)
+				}
+				if apiErr.Type != tt.expectedType {
+					t.Errorf("expected error %s, got %s", tt.expectedType, apiErr.Type)
+				}
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("expected %s in the body, got %s", tt.expectedBody, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// errBatchItemPreconditionRequired is the error of the update and delete items without ifMatch
+var errBatchItemPreconditionRequired = model.APIError{
+	Type:        model.ErrPreconditionRequired.Type,
+	HTTPCode:    model.ErrPreconditionRequired.HTTPCode,
+	Description: "this item must be conditional, please give the model version in its ifMatch",
+}
+
+const (
+	keyBatchTooManyItems      = i18n.KeyPrefixError + "batch_too_many_items"
+	keyBatchTooManyOperations = i18n.KeyPrefixError + "batch_too_many_operations"
+)
+
+// newBatchTooLargeError returns the error of the batches with more than max items or operations, described by key
+func newBatchTooLargeError(key string, max int) model.APIError {
+	apiErr := model.ErrRequestTooLarge
+	apiErr.MessageKey = key
+	apiErr.MessageArgs = []interface{}{max}
+	apiErr.Description = i18n.Sprintf(i18n.DefaultLanguage(), key, apiErr.Description, max)
+	return apiErr
+}
+
+// isBatchTooLarge reports whether a batch of count items or operations exceeds max, 0 meaning no limit
+func isBatchTooLarge(count, max int) bool {
+	return max > 0 && count > max
+}
+
+// batchResults holds the result of each item of a bulk request
+type batchResults []*model.BatchItemResult
+
+func newBatchResults(count int) batchResults {
+	results := make(batchResults, count)
+	for i := range results {
+		results[i] = &model.BatchItemResult{Index: i}
+	}
+	return results
+}
+
+func (r batchResults) setError(i int, e model.APIError) {
+	r[i].Status = e.HTTPCode
+	r[i].Data = nil
+	r[i].Error = &e
+}
+
+func (r batchResults) setData(i int, status int, data interface{}) {
+	r[i].Status = status
+	r[i].Data = data
+	r[i].Error = nil
+}
+
+// setDAOResults sets the results of the items given to a bulk dao func, from the errors it returned.
+// indexes gives the batch item index of each item given to the dao, and data the successful result of each of them.
+func (r batchResults) setDAOResults(c *gin.Context, indexes []int, errs []error, err error, status int, data func(j int) interface{}) {
+	if err != nil {
+		apiErr := batchItemDAOError(c, err)
+		for _, i := range indexes {
+			r.setError(i, apiErr)
+		}
+		return
+	}
+	for j, i := range indexes {
+		if errs[j] != nil {
+			r.setError(i, batchItemDAOError(c, errs[j]))
+			continue
+		}
+		r.setData(i, status, data(j))
+	}
+}
+
+func (r batchResults) failed() bool {
+	for _, result := range r {
+		if result.Error != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// abort marks all the items without error as aborted, when a batch in all or nothing mode failed
+func (r batchResults) abort() {
+	for i, result := range r {
+		if result.Error == nil {
+			r.setError(i, model.ErrBatchAborted)
+		}
+	}
+}
+
+func (r batchResults) response() model.BatchResponse {
+	return model.BatchResponse{
+		Results: r,
+	}
+}
+
+func isAtomicBatch(mode string) bool {
+	return mode != model.BatchModeBestEffort
+}
+
+// batchItemDAOError converts the error returned by a bulk dao func for one item to an APIError
+func batchItemDAOError(c *gin.Context, err error) model.APIError {
+	apiErr := httputils.NewAPIErrorFromError(err, "")
+	if apiErr.HTTPCode >= http.StatusInternalServerError {
+		utils.GetLoggerFromCtx(c).WithError(err).Error("error while processing batch item")
+	}
+	return apiErr
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/denouche/go-api-skeleton/storage/dao"
+	daoTracing "github.com/denouche/go-api-skeleton/storage/dao/tracing"
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/storage/validators"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/gin-gonic/gin"
+)
+
+type contextKey string
+
+const (
+	batchPath = "/_batch"
+
+	// contextKeyDatabase is the request context key of the database to use instead of the default one,
+	// set for the operations of a transactional batch
+	contextKeyDatabase contextKey = "database"
+)
+
+var (
+	errBatchOperationFailed = errors.New("batch operation failed")
+
+	// batchInheritedHeaders are the headers of the batch request given to each of its operations
+	batchInheritedHeaders = []string{
+		httputils.HeaderNameAuthorization,
+		httputils.HeaderNameCorrelationID,
+	}
+)
+
+// getDB returns the database to use for the request, bound to a transaction when the request is part of a transactional batch.
+// Its calls are traced in the request span.
+func (hc *Context) getDB(c *gin.Context) dao.Database {
+	if db, ok := c.Request.Context().Value(contextKeyDatabase).(dao.Database); ok {
+		return daoTracing.NewDatabaseTracing(c.Request.Context(), db)
+	}
+	return daoTracing.NewDatabaseTracing(c.Request.Context(), hc.db)
+}
+
+// @openapi:path
+// /_batch:
+//	post:
+//		tags:
+//			- batch
+//		description: "Execute several API calls in one request. The operations are executed in order, through the same routes and middlewares as the other requests. When transactional is true, the operations are made in a single database transaction, stopped and rolled back at the first failed operation."
+//		requestBody:
+//			description: The operations to execute.
+//			required: true
+//			content:
+//				application/json:
+//					schema:
+//						$ref: "#/components/schemas/BatchOperationsRequest"
+//		responses:
+//			200:
+//				description: "The response of each operation, in the same order as in the request"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/BatchOperationsResponse"
+//			400:
+//				description: "This error occurs when the request is not correct (bad body format, validation error)"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			413:
+//				description: "This error occurs when the request body is too large, or the batch has too many operations"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			500:
+//				description: "Server error"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+func (hc *Context) GetBatchHandler(router http.Handler) func(*gin.Context) {
+	return func(c *gin.Context) {
+		body, err := c.GetRawData()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		request := model.BatchOperationsRequest{}
+		err = json.Unmarshal(body, &request)
+		if err != nil {
+			httputils.JSONError(c, model.ErrBadRequestFormat)
+			return
+		}
+		if isBatchTooLarge(len(request.Operations), hc.config.BatchMaxOperations) {
+			httputils.JSONError(c, newBatchTooLargeError(keyBatchTooManyOperations, hc.config.BatchMaxOperations))
+			return
+		}
+
+		err = hc.validator.StructCtx(validators.NewContextWithValidationContext(c, hc.getDB(c)), request)
+		if err != nil {
+			httputils.JSONError(c, validators.NewDataValidationAPIError(err))
+			return
+		}
+
+		responses := make([]*model.BatchOperationResponse, len(request.Operations))
+		if !request.Transactional {
+			for i, op := range request.Operations {
+				responses[i] = hc.executeBatchOperation(c.Request.Context(), c, router, op)
+			}
+			httputils.JSON(c.Writer, http.StatusOK, model.BatchOperationsResponse{Responses: responses})
+			return
+		}
+
+		err = hc.getDB(c).WithTransaction(func(tx dao.Database) error {
+			ctx := context.WithValue(c.Request.Context(), contextKeyDatabase, tx)
+			for i, op := range request.Operations {
+				responses[i] = hc.executeBatchOperation(ctx, c, router, op)
+				if responses[i].Status >= http.StatusBadRequest {
+					return errBatchOperationFailed
+				}
+			}
+			return nil
+		})
+		if err == errBatchOperationFailed {
+			// the transaction has been rolled back, only the failed operation keeps its response
+			for i, response := range responses {
+				if response == nil || response.Status < http.StatusBadRequest {
+					responses[i] = newBatchOperationErrorResponse(model.ErrBatchAborted)
+				}
+			}
+		} else if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		httputils.JSON(c.Writer, http.StatusOK, model.BatchOperationsResponse{Responses: responses})
+	}
+}
+
+// executeBatchOperation dispatches an operation of a batch through the router, and records its response
+func (hc *Context) executeBatchOperation(ctx context.Context, c *gin.Context, router http.Handler, op model.BatchOperation) *model.BatchOperationResponse {
+	path := strings.SplitN(op.Path, "?", 2)[0]
+	if path == batchPath {
+		apiErr := model.ErrDataValidation
+		apiErr.Description = "a batch cannot contain another batch"
+		return newBatchOperationErrorResponse(apiErr)
+	}
+
+	req, err := http.NewRequest(op.Method, op.Path, bytes.NewReader(op.Body))
+	if err != nil {
+		apiErr := model.ErrDataValidation
+		apiErr.Description = "the operation path is not valid"
+		return newBatchOperationErrorResponse(apiErr)
+	}
+	req = req.WithContext(ctx)
+	req.RemoteAddr = c.Request.RemoteAddr
+	for _, h := range batchInheritedHeaders {
+		if v := c.GetHeader(h); v != "" {
+			req.Header.Set(h, v)
+		}
+	}
+	if req.Header.Get(httputils.HeaderNameCorrelationID) == "" {
+		// the correlationID generated for the batch request is used for all its operations
+		req.Header.Set(httputils.HeaderNameCorrelationID, c.Writer.Header().Get(httputils.HeaderNameCorrelationID))
+	}
+	if len(op.Body) > 0 {
+		req.Header.Set(httputils.HeaderNameContentType, httputils.HeaderValueApplicationJSONUTF8)
+	}
+	for k, v := range op.Headers {
+		req.Header.Set(k, v)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	response := &model.BatchOperationResponse{
+		Status:  recorder.Code,
+		Headers: make(map[string]string),
+	}
+	for k, values := range recorder.Header() {
+		response.Headers[k] = strings.Join(values, ", ")
+	}
+	if b := recorder.Body.Bytes(); len(b) > 0 {
+		if json.Valid(b) {
+			response.Body = b
+		} else {
+			// not a json response, give it as a json string
+			response.Body, _ = json.Marshal(string(b))
+		}
+	}
+	return response
+}
+
+func newBatchOperationErrorResponse(e model.APIError) *model.BatchOperationResponse {
+	b, _ := json.Marshal(e)
+	return &model.BatchOperationResponse{
+		Status: e.HTTPCode,
+		Headers: map[string]string{
+			http.CanonicalHeaderKey(httputils.HeaderNameContentType): httputils.HeaderValueApplicationJSONUTF8,
+		},
+		Body: b,
+	}
+}
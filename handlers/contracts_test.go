@@ -0,0 +1,46 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/handlers"
+	"github.com/denouche/go-api-skeleton/pkg/client/contracttest"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
+)
+
+const contractsDir = "../contracts"
+
+// TestContracts replays the contracts recorded by the consumers of the api, like the contracts verify command,
+// for a change breaking a consumer to fail the tests
+func TestContracts(t *testing.T) {
+	utils.InitLogger("error", utils.LogFormatText)
+	if err := i18n.Init("", "en"); err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := contracttest.LoadDir(contractsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contracts) == 0 {
+		t.Fatalf("no contracts in %s", contractsDir)
+	}
+
+	for _, contract := range contracts {
+		contract := contract
+		t.Run(contract.Consumer, func(t *testing.T) {
+			// each contract is replayed against a new api, its interactions depending only on the previous ones
+			hc := handlers.NewContext(&handlers.Config{
+				DBInMemory:                true,
+				IdempotencyKeyTTL:         24 * time.Hour,
+				OpenAPIValidation:         true,
+				OpenAPIResponseValidation: true,
+			})
+			if err := contracttest.Verify(handlers.NewRouter(hc), contract); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
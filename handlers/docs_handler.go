@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/denouche/go-api-skeleton/middlewares"
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+const docsAssetsCacheControl = "public, max-age=86400"
+
+// docsAssets are the Swagger UI files served under /docs
+var docsAssets = map[string]bool{
+	"swagger-ui.css":       true,
+	"swagger-ui-bundle.js": true,
+	"favicon-16x16.png":    true,
+	"favicon-32x32.png":    true,
+	"oauth2-redirect.html": true,
+}
+
+var docsPageTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="UTF-8">
+	<title>{{ .Title }}</title>
+	<link rel="stylesheet" type="text/css" href="{{ .BaseURI }}/docs/swagger-ui.css">
+	<link rel="icon" type="image/png" href="{{ .BaseURI }}/docs/favicon-32x32.png" sizes="32x32">
+	<link rel="icon" type="image/png" href="{{ .BaseURI }}/docs/favicon-16x16.png" sizes="16x16">
+</head>
+<body>
+	<div id="swagger-ui"></div>
+	<script src="{{ .BaseURI }}/docs/swagger-ui-bundle.js"></script>
+	<script>
+		var options = {{ .Options }};
+		options.dom_id = "#swagger-ui";
+		options.presets = [SwaggerUIBundle.presets.apis];
+		window.ui = SwaggerUIBundle(options);
+	</script>
+</body>
+</html>
+`))
+
+// newDocsPage renders the Swagger UI page of the embedded OpenAPI document. The "try it out" requests are restricted
+// to the methods allowed by the CORS policy and sent with credentials when it allows them, as the servers of the
+// document can be on another origin. The authorizations given in the page are kept across reloads.
+func newDocsPage(tryItOut bool, cors *middlewares.CORSPolicy, withCredentials bool) ([]byte, error) {
+	submitMethods := []string{}
+	if tryItOut {
+		methods := cors.AllowedMethods(http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+			http.MethodPatch, http.MethodDelete)
+		for _, m := range methods {
+			submitMethods = append(submitMethods, strings.ToLower(m))
+		}
+	}
+
+	title := "API documentation"
+	if ApplicationName != "" {
+		title = ApplicationName + " " + title
+	}
+
+	var page bytes.Buffer
+	err := docsPageTemplate.Execute(&page, map[string]interface{}{
+		"Title":   title,
+		"BaseURI": baseURI,
+		"Options": map[string]interface{}{
+			"url":                    baseURI + "/openapi.json",
+			"supportedSubmitMethods": submitMethods,
+			"withCredentials":        withCredentials,
+			"persistAuthorization":   true,
+			"oauth2RedirectUrl":      baseURI + "/docs/oauth2-redirect.html",
+			"deepLinking":            true,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page.Bytes(), nil
+}
+
+func (hc *Context) GetDocs(c *gin.Context) {
+	httputils.HTML(c.Writer, http.StatusOK, hc.docsPage)
+}
+
+func (hc *Context) GetDocsAsset(c *gin.Context) {
+	name := strings.TrimPrefix(c.Param("filepath"), "/")
+	if name == "" {
+		hc.GetDocs(c)
+		return
+	}
+	if !docsAssets[name] {
+		httputils.JSONErrorWithMessage(c, model.ErrNotFound, "Documentation file not found")
+		return
+	}
+	c.Writer.Header().Set(httputils.HeaderNameCacheControl, docsAssetsCacheControl)
+	c.FileFromFS(name, http.FS(swaggerFiles.FS))
+}
@@ -3,7 +3,11 @@ package handlers
 import (
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/denouche/go-api-skeleton/api"
 	"github.com/denouche/go-api-skeleton/middlewares"
 	"github.com/denouche/go-api-skeleton/storage/dao"
 	dbFake "github.com/denouche/go-api-skeleton/storage/dao/fake" // DAO IN MEMORY
@@ -12,8 +16,8 @@ import (
 	"github.com/denouche/go-api-skeleton/storage/dao/postgresql" // DAO PG
 	"github.com/denouche/go-api-skeleton/storage/validators"
 	"github.com/denouche/go-api-skeleton/utils"
-	"github.com/denouche/go-api-skeleton/utils/httputils"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gopkg.in/go-playground/validator.v9"
 )
 
@@ -29,23 +33,70 @@ var (
 )
 
 type Config struct {
-	Mock                 bool
-	DBInMemory           bool   // DAO IN MEMORY
-	DBInMemoryImportFile string // DAO IN MEMORY
-	DBConnectionURI      string
-	DBName               string
-	Port                 int
-	LogLevel             string
-	LogFormat            string
+	Mock                      bool
+	DBInMemory                bool   // DAO IN MEMORY
+	DBInMemoryImportFile      string // DAO IN MEMORY
+	DBConnectionURI           string
+	DBName                    string
+	Port                      int
+	LogLevel                  string
+	LogFormat                 string
+	LogComponentLevels        []string
+	LogSamplingInitial        int
+	LogSamplingThereafter     int
+	ErrorFormat               string
+	ProblemTypeBaseURI        string
+	MessagesDir               string
+	DefaultLanguage           string
+	CORSAllowedOrigins        []string
+	CORSAllowedMethods        []string
+	CORSAllowedHeaders        []string
+	CORSExposedHeaders        []string
+	CORSMaxAge                time.Duration
+	CORSAllowCredentials      bool
+	IdempotencyKeyTTL         time.Duration
+	MaxRequestBodySize        int64 // 0 for no limit
+	BatchMaxItems             int   // 0 for no limit
+	BatchMaxOperations        int   // 0 for no limit
+	ValidationRulesFile       string
+	AdmissionWebhooksFile     string
+	OpenAPIValidation         bool
+	OpenAPIResponseValidation bool
+	Docs                      bool
+	DocsTryItOut              bool
+	HealthCheckTimeout        time.Duration
+	ShutdownDelay             time.Duration
+	ShutdownTimeout           time.Duration
+	Metrics                   bool
+	AdminAddress              string
+	TracingExporter           string
+	TracingOTLPEndpoint       string
+	TracingSampleRatio        float64
+	TracingServiceName        string
 }
 
 type Context struct {
+	config    Config
 	db        dao.Database
 	validator *validator.Validate
+	cors      *middlewares.CORSPolicy
+	openAPI   *middlewares.OpenAPIValidator
+	docsPage  []byte
+
+	httpMetrics    *middlewares.HTTPMetrics
+	metricsHandler http.Handler
+
+	idempotencyKeyTTL time.Duration
+
+	healthLock         sync.RWMutex
+	healthChecks       []healthCheck
+	healthCheckTimeout time.Duration
+	healthState        atomic.Int32
 }
 
 func NewContext(config *Config) *Context {
 	hc := &Context{}
+	hc.config = redactConfig(*config)
 	if config.Mock {
 		hc.db = dbMock.NewDatabaseMock()
 	} else if config.DBInMemory { // DAO IN MEMORY
@@ -58,6 +109,39 @@ func NewContext(config *Config) *Context {
 		utils.GetLogger().Fatal("no db connection uri given or not handled, and no db in memory mode enabled, exiting")
 	}
 	hc.validator = validators.NewValidator()
+	hc.cors = middlewares.NewCORSPolicy(middlewares.CORSConfig{
+		AllowedOrigins:   config.CORSAllowedOrigins,
+		AllowedMethods:   config.CORSAllowedMethods,
+		AllowedHeaders:   config.CORSAllowedHeaders,
+		ExposedHeaders:   config.CORSExposedHeaders,
+		MaxAge:           config.CORSMaxAge,
+		AllowCredentials: config.CORSAllowCredentials,
+	})
+	if config.OpenAPIValidation || config.OpenAPIResponseValidation {
+		openAPI, err := middlewares.NewOpenAPIValidator([]byte(api.OpenAPISchema), middlewares.OpenAPIValidatorConfig{
+			ValidateRequests:  config.OpenAPIValidation,
+			ValidateResponses: config.OpenAPIResponseValidation,
+		})
+		if err != nil {
+			utils.GetLogger().WithError(err).Fatal("error while loading the openapi document")
+		}
+		hc.openAPI = openAPI
+	}
+	if config.Docs {
+		docsPage, err := newDocsPage(config.DocsTryItOut, hc.cors, config.CORSAllowCredentials)
+		if err != nil {
+			utils.GetLogger().WithError(err).Fatal("error while rendering the documentation page")
+		}
+		hc.docsPage = docsPage
+	}
+	if config.Metrics {
+		registry := newMetricsRegistry(hc.db)
+		hc.httpMetrics = middlewares.NewHTTPMetrics(registry)
+		hc.metricsHandler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	}
+	hc.idempotencyKeyTTL = config.IdempotencyKeyTTL
+	hc.healthCheckTimeout = config.HealthCheckTimeout
+	hc.RegisterHealthCheck(healthCheckDatabase, hc.db)
 	return hc
 }
 
@@ -67,9 +151,17 @@ func NewRouter(hc *Context) *gin.Engine {
 	router := gin.New()
 	router.HandleMethodNotAllowed = true
 
+	if hc.httpMetrics != nil {
+		// outside the recovery, to count the requests whose handler panicked with their 500 status
+		router.Use(middlewares.GetMetricsMiddleware(hc.httpMetrics))
+	}
 	router.Use(gin.Recovery())
+	router.Use(middlewares.GetTracingMiddleware())
 	router.Use(middlewares.GetLoggerMiddleware())
 	router.Use(middlewares.GetHTTPLoggerMiddleware())
+	router.Use(middlewares.GetLanguageMiddleware())
+	router.Use(middlewares.GetErrorMiddleware())
+	router.Use(middlewares.GetRequestBodySizeMiddleware(hc.config.MaxRequestBodySize))
 
 	handleAPIRoutes(hc, router)
 	handleCORSRoutes(hc, router)
@@ -77,40 +169,53 @@ func NewRouter(hc *Context) *gin.Engine {
 	return router
 }
 
+// handleCORSRoutes registers an OPTIONS route for each path of the router, advertising the methods registered for it
 func handleCORSRoutes(hc *Context, router *gin.Engine) {
-	public := router.Group(baseURI)
-
-	public.Handle(http.MethodOptions, "/_health", hc.GetOptionsHandler(httputils.AllowedHeaders, http.MethodGet))
-	public.Handle(http.MethodOptions, "/openapi", hc.GetOptionsHandler(httputils.AllowedHeaders, http.MethodGet))
-
-	// start: template routes
-	public.Handle(http.MethodOptions, "/templates", hc.GetOptionsHandler(httputils.AllowedHeaders, http.MethodGet, http.MethodPost))
-	public.Handle(http.MethodOptions, "/templates/:id", hc.GetOptionsHandler(httputils.AllowedHeaders, http.MethodGet, http.MethodPut, http.MethodDelete))
-	// end: template routes
+	routes := newRouteTable(router.Routes())
+	for _, path := range routes.paths {
+		router.Handle(http.MethodOptions, path, hc.GetOptionsHandler(routes.methods[path]...))
+	}
+	router.NoMethod(hc.GetMethodNotAllowedHandler(routes))
 }
 
 func handleAPIRoutes(hc *Context, router *gin.Engine) {
 	public := router.Group(baseURI)
-	public.Use(middlewares.GetCORSMiddlewareForOthersHTTPMethods())
+	public.Use(middlewares.GetCORSMiddlewareForOthersHTTPMethods(hc.cors))
+	if hc.openAPI != nil {
+		public.Use(middlewares.GetOpenAPIValidationMiddleware(hc.openAPI))
+	}
 
-	public.Handle(http.MethodGet, "/_health", hc.GetHealth)
 	public.Handle(http.MethodGet, "/openapi", hc.GetOpenAPISchema)
-
-	if dbInMemory, ok := hc.db.(*dbFake.DatabaseFake); ok { // DAO IN MEMORY
-		// db in memory mode, add export endpoint // DAO IN MEMORY
-		public.Handle(http.MethodGet, "/export", func(c *gin.Context) { // DAO IN MEMORY
-			httputils.JSON(c.Writer, http.StatusOK, dbInMemory.Export()) // DAO IN MEMORY
-		}) // DAO IN MEMORY
-	} // DAO IN MEMORY
+	public.Handle(http.MethodHead, "/openapi", hc.GetOpenAPISchema)
+	public.Handle(http.MethodGet, "/openapi.json", hc.GetOpenAPISchemaJSON)
+	public.Handle(http.MethodHead, "/openapi.json", hc.GetOpenAPISchemaJSON)
+
+	if hc.docsPage != nil {
+		public.Handle(http.MethodGet, "/docs", hc.GetDocs)
+		public.Handle(http.MethodHead, "/docs", hc.GetDocs)
+		public.Handle(http.MethodGet, "/docs/*filepath", hc.GetDocsAsset)
+		public.Handle(http.MethodHead, "/docs/*filepath", hc.GetDocsAsset)
+	}
 
 	secured := public.Group("/")
 	// you can add an authentication middleware here
+	secured.Use(middlewares.GetIdempotencyMiddleware(hc.getDB, hc.idempotencyKeyTTL))
+
+	secured.Handle(http.MethodPost, batchPath, hc.GetBatchHandler(router))
 
 	// start: template routes
 	secured.Handle(http.MethodGet, "/templates", hc.GetAllTemplates)
+	secured.Handle(http.MethodHead, "/templates", hc.GetAllTemplates)
 	secured.Handle(http.MethodPost, "/templates", hc.CreateTemplate)
+	secured.Handle(http.MethodPost, "/templates:"+customMethodParam, getCustomMethodsHandler(map[string]gin.HandlerFunc{
+		"batch":       hc.CreateTemplates,
+		"batchUpdate": hc.UpdateTemplates,
+		"batchDelete": hc.DeleteTemplates,
+	}))
 	secured.Handle(http.MethodGet, "/templates/:id", hc.GetTemplate)
+	secured.Handle(http.MethodHead, "/templates/:id", hc.GetTemplate)
 	secured.Handle(http.MethodPut, "/templates/:id", hc.UpdateTemplate)
+	secured.Handle(http.MethodPatch, "/templates/:id", hc.PatchTemplate)
 	secured.Handle(http.MethodDelete, "/templates/:id", hc.DeleteTemplate)
 	// end: template routes
 }
@@ -1,12 +1,66 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"sync"
+	"time"
 
+	"github.com/denouche/go-api-skeleton/storage/dao"
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils"
 	"github.com/denouche/go-api-skeleton/utils/httputils"
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	healthCheckDatabase = "database"
+	healthCacheControl  = "no-store"
+)
+
+const (
+	healthStateStarting int32 = iota
+	healthStateReady
+	healthStateStopping
+)
+
+// HealthCheckFunc is a dao.HealthChecker calling itself
+type HealthCheckFunc func(ctx context.Context) error
+
+func (f HealthCheckFunc) CheckHealth(ctx context.Context) error {
+	return f(ctx)
+}
+
+type healthCheck struct {
+	name    string
+	checker dao.HealthChecker
+}
+
+// RegisterHealthCheck adds a check to the readiness endpoint, which is down while the check fails.
+// A check registered with the name of an existing one replaces it.
+func (hc *Context) RegisterHealthCheck(name string, checker dao.HealthChecker) {
+	hc.healthLock.Lock()
+	defer hc.healthLock.Unlock()
+	for i, check := range hc.healthChecks {
+		if check.name == name {
+			hc.healthChecks[i].checker = checker
+			return
+		}
+	}
+	hc.healthChecks = append(hc.healthChecks, healthCheck{name: name, checker: checker})
+}
+
+// SetReady sets whether the api is ready to serve requests. It is not ready until its startup is done,
+// and no longer ready once its shutdown begins, for the load balancers to stop sending it requests.
+func (hc *Context) SetReady(ready bool) {
+	if ready {
+		hc.healthState.Store(healthStateReady)
+	} else {
+		hc.healthState.CompareAndSwap(healthStateReady, healthStateStopping)
+	}
+}
+
 func (hc *Context) GetHealth(c *gin.Context) {
 	conf := map[string]string{
 		"ApplicationName":      ApplicationName,
@@ -16,3 +70,88 @@ func (hc *Context) GetHealth(c *gin.Context) {
 	}
 	httputils.JSON(c.Writer, http.StatusOK, conf)
 }
+
+// GetHealthLive tells the api is alive. The dependencies are not checked, the api must be restarted only when it
+// does not answer.
+func (hc *Context) GetHealthLive(c *gin.Context) {
+	httputils.JSON(c.Writer, http.StatusOK, &model.HealthReport{Status: model.HealthStatusUp})
+}
+
+// GetHealthReady tells whether the api is ready to serve requests: its startup is done, its shutdown has not begun,
+// and its dependencies like the database answer. It answers 503 Service Unavailable when it is not ready.
+func (hc *Context) GetHealthReady(c *gin.Context) {
+	report := &model.HealthReport{
+		Status: model.HealthStatusUp,
+		Checks: hc.checkHealth(c.Request.Context()),
+	}
+	for _, result := range report.Checks {
+		if result.Status != model.HealthStatusUp {
+			report.Status = model.HealthStatusDown
+		}
+	}
+	switch hc.healthState.Load() {
+	case healthStateStarting:
+		report.Status = model.HealthStatusStarting
+	case healthStateStopping:
+		report.Status = model.HealthStatusStopping
+	}
+
+	status := http.StatusOK
+	if report.Status != model.HealthStatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	// the readiness changes at any time, it must be checked again by each probe
+	c.Writer.Header().Set(httputils.HeaderNameCacheControl, healthCacheControl)
+	httputils.JSON(c.Writer, status, report)
+}
+
+// checkHealth runs the registered checks concurrently, each one limited by the health check timeout if any
+func (hc *Context) checkHealth(ctx context.Context) map[string]*model.HealthCheckResult {
+	hc.healthLock.RLock()
+	checks := append([]healthCheck{}, hc.healthChecks...)
+	hc.healthLock.RUnlock()
+
+	results := make(map[string]*model.HealthCheckResult, len(checks))
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check healthCheck) {
+			defer wg.Done()
+			checkCtx := ctx
+			if hc.healthCheckTimeout > 0 {
+				var cancel context.CancelFunc
+				checkCtx, cancel = context.WithTimeout(ctx, hc.healthCheckTimeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			err := runHealthCheck(checkCtx, check)
+			result := &model.HealthCheckResult{
+				Status:    model.HealthStatusUp,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = model.HealthStatusDown
+				result.Error = err.Error()
+			}
+
+			lock.Lock()
+			results[check.name] = result
+			lock.Unlock()
+		}(check)
+	}
+	wg.Wait()
+	return results
+}
+
+// runHealthCheck runs a check, a panic of the check making it down instead of crashing the api
+func runHealthCheck(ctx context.Context, check healthCheck) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("health check panicked: %v", r)
+			utils.GetLogger().WithError(err).WithField("check", check.name).Error("health check panicked")
+		}
+	}()
+	return check.checker.CheckHealth(ctx)
+}
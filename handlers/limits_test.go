@@ -0,0 +1,102 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/handlers"
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
+)
+
+func TestRequestLimits(t *testing.T) {
+	utils.InitLogger("error", utils.LogFormatText)
+	if err := i18n.Init("", "en"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                 string
+		path                 string
+		body                 string
+		unknownContentLength bool
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:         "bulk request at the maximum number of items",
+			path:         "/templates:batch",
+			body:         `{"items":[{"name":"a"},{"name":"b"}]}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "bulk request with too many items",
+			path:         "/templates:batch",
+			body:         `{"items":[{"name":"a"},{"name":"b"},{"name":"c"}]}`,
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedBody: "at most 2 items",
+		},
+		{
+			name:         "batch with too many operations",
+			path:         "/_batch",
+			body:         `{"operations":[{"method":"GET","path":"/templates"},{"method":"GET","path":"/templates"},{"method":"GET","path":"/templates"}]}`,
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedBody: "at most 2 operations",
+		},
+		{
+			name:         "body larger than the maximum size",
+			path:         "/templates",
+			body:         `{"name":"` + strings.Repeat("a", 200) + `"}`,
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:                 "body of unknown length larger than the maximum size",
+			path:                 "/templates",
+			body:                 `{"name":"` + strings.Repeat("a", 200) + `"}`,
+			unknownContentLength: true,
+			expectedCode:         http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			router := handlers.NewRouter(handlers.NewContext(&handlers.Config{
+				DBInMemory:         true,
+				IdempotencyKeyTTL:  24 * time.Hour,
+				OpenAPIValidation:  true,
+				MaxRequestBodySize: 160,
+				BatchMaxItems:      2,
+				BatchMaxOperations: 2,
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			if tt.unknownContentLength {
+				r.ContentLength = -1
+			}
+			router.ServeHTTP(w, r)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body)
+			}
+			if tt.expectedCode == http.StatusRequestEntityTooLarge {
+				apiErr := model.APIError{}
+				if err := json.Unmarshal(w.Body.Bytes(), &apiErr); err != nil {
+					t.Fatal(err)
+				}
+				if apiErr.Type != model.ErrRequestTooLarge.Type {
+					t.Errorf("expected error %s, got %s", model.ErrRequestTooLarge.Type, apiErr.Type)
+				}
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("expected %s in the body, got %s", tt.expectedBody, w.Body)
+			}
+		})
+	}
+}
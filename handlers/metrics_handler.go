@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/denouche/go-api-skeleton/storage/dao"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// newMetricsRegistry returns a registry of the Go runtime and process metrics, the build info of the api,
+// and the metrics of db when it exposes some
+func newMetricsRegistry(db dao.Database) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "app_build_info",
+			Help: "Build info of the api, always 1.",
+			ConstLabels: prometheus.Labels{
+				"name":       ApplicationName,
+				"version":    ApplicationVersion,
+				"git_hash":   ApplicationGitHash,
+				"build_date": ApplicationBuildDate,
+			},
+		}, func() float64 { return 1 }),
+	)
+	if c, ok := db.(dao.MetricsCollector); ok {
+		registry.MustRegister(c.Collector())
+	}
+	return registry
+}
+
+// GetMetrics sends the metrics in the Prometheus text format
+func (hc *Context) GetMetrics(c *gin.Context) {
+	hc.metricsHandler.ServeHTTP(c.Writer, c.Request)
+}
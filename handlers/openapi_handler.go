@@ -2,12 +2,30 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/denouche/go-api-skeleton/api"
 	"github.com/denouche/go-api-skeleton/utils/httputils"
 	"github.com/gin-gonic/gin"
 )
 
+// GetOpenAPISchema sends the OpenAPI document in YAML, or in JSON when the client prefers it in its Accept header
 func (hc *Context) GetOpenAPISchema(c *gin.Context) {
+	// the representation depends on the Accept header, caches must take it into account
+	c.Writer.Header().Add(httputils.HeaderNameVary, http.CanonicalHeaderKey(httputils.HeaderNameAccept))
+
+	for _, mediaType := range httputils.AcceptedMediaTypes(c) {
+		if strings.EqualFold(mediaType, httputils.HeaderValueApplicationJSON) {
+			hc.GetOpenAPISchemaJSON(c)
+			return
+		}
+		if strings.HasSuffix(strings.ToLower(mediaType), "yaml") {
+			break
+		}
+	}
 	httputils.YAML(c.Writer, http.StatusOK, api.OpenAPISchema)
 }
+
+func (hc *Context) GetOpenAPISchemaJSON(c *gin.Context) {
+	httputils.RawJSON(c.Writer, http.StatusOK, api.OpenAPISchemaJSON)
+}
@@ -4,38 +4,54 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/denouche/go-api-skeleton/storage/model"
 	"github.com/denouche/go-api-skeleton/utils/httputils"
 	"github.com/gin-gonic/gin"
 )
 
-func (hc *Context) GetOptionsHandler(allowedHeaders []string, allowedMethods ...string) func(*gin.Context) {
+func (hc *Context) GetOptionsHandler(allowedMethods ...string) func(*gin.Context) {
+	allow := strings.Join(append(append([]string{}, allowedMethods...), http.MethodOptions), ", ")
 	return func(c *gin.Context) {
+		hc.cors.SetVaryHeader(c.Writer)
 		headerOrigin := c.Request.Header.Get(httputils.HeaderNameOrigin)
-		if headerOrigin != "" {
-			optionsInArray := false
-			for _, method := range allowedMethods {
-				if method == http.MethodOptions {
-					optionsInArray = true
-					break
-				}
-			}
-			if !optionsInArray {
-				allowedMethods = append(allowedMethods, http.MethodOptions)
-			}
+		if headerOrigin == "" {
+			// not a CORS preflight request, only advertise the methods of the resource
+			c.Writer.Header().Set(httputils.HeaderNameAllow, allow)
+			c.Status(http.StatusOK)
+			return
+		}
 
-			c.Writer.Header().Set(httputils.HeaderNameAccessControlAllowOrigin, headerOrigin)
-			if headerOrigin == "*" {
-				c.Writer.Header().Set(httputils.HeaderNameAccessControlAllowCredentials, "false")
-			} else {
-				c.Writer.Header().Set(httputils.HeaderNameAccessControlAllowCredentials, "true")
-			}
+		methods := hc.cors.AllowedMethods(allowedMethods...)
+		requestMethod := c.Request.Header.Get(httputils.HeaderNameAccessControlRequestMethod)
+		if !hc.cors.IsOriginAllowed(headerOrigin) ||
+			(requestMethod != "" && !containsMethod(methods, requestMethod)) ||
+			!hc.cors.AreHeadersAllowed(c.Request.Header.Get(httputils.HeaderNameAccessControlRequestHeaders)) {
+			// no CORS headers are sent, so the browser will block the request
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		hc.cors.SetPreflightHeaders(c.Writer, headerOrigin, methods)
+		c.Status(http.StatusOK)
+	}
+}
 
-			c.Writer.Header().Set(httputils.HeaderNameAccessControlAllowMethods, strings.Join(allowedMethods, ","))
-			c.Writer.Header().Set(httputils.HeaderNameAccessControlAllowHeaders, strings.Join(allowedHeaders, ","))
+// GetMethodNotAllowedHandler returns the handler used when a path exists but not for the requested method.
+// It sets the Allow header with the methods registered for the path.
+func (hc *Context) GetMethodNotAllowedHandler(routes *routeTable) func(*gin.Context) {
+	return func(c *gin.Context) {
+		if methods := routes.methodsFor(c.Request.URL.Path); len(methods) > 0 {
+			c.Writer.Header().Set(httputils.HeaderNameAllow, strings.Join(methods, ", "))
+		}
+		httputils.JSONError(c, model.ErrMethodNotAllowed)
+	}
+}
 
-			c.Status(http.StatusOK)
-			return
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
 		}
-		c.Status(http.StatusMethodNotAllowed)
 	}
+	return false
 }
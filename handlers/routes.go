@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/gin-gonic/gin"
+)
+
+// customMethodParam is the param of the routes of the custom methods of a collection, like /templates:method
+const customMethodParam = "method"
+
+// getCustomMethodsHandler dispatches the requests of the custom methods of a collection, like POST /templates:batch,
+// to the handler of their method name. They are routed by a single route with a param in the last segment,
+// /templates:method, gin not routing the paths with a colon otherwise. The method is read from the request path,
+// the unknown ones are not found.
+func getCustomMethodsHandler(handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		segment := c.Request.URL.Path[strings.LastIndex(c.Request.URL.Path, "/")+1:]
+		i := strings.Index(segment, ":")
+		if i < 0 {
+			httputils.JSONError(c, model.ErrNotFound)
+			return
+		}
+		handler, ok := handlers[segment[i+1:]]
+		if !ok {
+			httputils.JSONError(c, model.ErrNotFound)
+			return
+		}
+		handler(c)
+	}
+}
+
+// routeTable lists, for each registered path, the HTTP methods handled by the router
+type routeTable struct {
+	paths   []string
+	methods map[string][]string
+}
+
+func newRouteTable(routes gin.RoutesInfo) *routeTable {
+	t := &routeTable{
+		methods: make(map[string][]string),
+	}
+	for _, r := range routes {
+		if _, ok := t.methods[r.Path]; !ok {
+			t.paths = append(t.paths, r.Path)
+		}
+		if !containsMethod(t.methods[r.Path], r.Method) {
+			t.methods[r.Path] = append(t.methods[r.Path], r.Method)
+		}
+	}
+	sort.Strings(t.paths)
+	for _, methods := range t.methods {
+		sort.Strings(methods)
+	}
+	return t
+}
+
+// methodsFor returns the methods allowed for the given request path, including OPTIONS.
+// When several paths match, the most specific one is used, like gin routes the requests.
+func (t *routeTable) methodsFor(requestPath string) []string {
+	matched := ""
+	for _, path := range t.paths {
+		if matchRoutePath(path, requestPath) && (matched == "" || isMoreSpecificRoutePath(path, matched)) {
+			matched = path
+		}
+	}
+	if matched == "" {
+		return nil
+	}
+	methods := append([]string{}, t.methods[matched]...)
+	if !containsMethod(methods, http.MethodOptions) {
+		methods = append(methods, http.MethodOptions)
+	}
+	return methods
+}
+
+// segment kinds of the route paths, from the most specific
+const (
+	routeSegmentStatic = iota
+	routeSegmentParam
+	routeSegmentCatchAll
+)
+
+// isMoreSpecificRoutePath compares two route paths matching the same request path: at their first segment of a
+// different kind, a static segment is more specific than a :param, which is more specific than a *catchAll
+func isMoreSpecificRoutePath(path, other string) bool {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	otherSegments := strings.Split(strings.Trim(other, "/"), "/")
+	for i := 0; i < len(segments) && i < len(otherSegments); i++ {
+		kind, otherKind := routeSegmentKind(segments[i]), routeSegmentKind(otherSegments[i])
+		if kind != otherKind {
+			return kind < otherKind
+		}
+	}
+	return len(segments) > len(otherSegments)
+}
+
+func routeSegmentKind(segment string) int {
+	switch {
+	case strings.HasPrefix(segment, "*"):
+		return routeSegmentCatchAll
+	case strings.Contains(segment, ":"):
+		return routeSegmentParam
+	default:
+		return routeSegmentStatic
+	}
+}
+
+// matchRoutePath checks if a request path matches a gin route path, handling :param, *catchAll segments and the
+// params after a static prefix, like templates:method
+func matchRoutePath(routePath, requestPath string) bool {
+	routeSegments := strings.Split(strings.Trim(routePath, "/"), "/")
+	requestSegments := strings.Split(strings.Trim(requestPath, "/"), "/")
+	for i, segment := range routeSegments {
+		if strings.HasPrefix(segment, "*") {
+			return true
+		}
+		if i >= len(requestSegments) {
+			return false
+		}
+		if j := strings.Index(segment, ":"); j >= 0 {
+			if len(requestSegments[i]) <= j || requestSegments[i][:j] != segment[:j] {
+				return false
+			}
+			continue
+		}
+		if segment != requestSegments[i] {
+			return false
+		}
+	}
+	return len(routeSegments) == len(requestSegments)
+}
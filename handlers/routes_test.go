@@ -0,0 +1,123 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/handlers"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
+)
+
+func TestCustomMethodRoutes(t *testing.T) {
+	utils.InitLogger("error", utils.LogFormatText)
+	if err := i18n.Init("", "en"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		body          string
+		expectedCode  int
+		expectedBody  string
+		expectedAllow string
+	}{
+		{
+			name:         "bulk create",
+			method:       http.MethodPost,
+			path:         "/templates:batch",
+			body:         `{"items":[{"name":"foo"}]}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `"status":201`,
+		},
+		{
+			name:         "bulk update",
+			method:       http.MethodPost,
+			path:         "/templates:batchUpdate",
+			body:         `{"items":[{"id":"unknown","ifMatch":"\"etag\"","data":{"name":"foo"}}]}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `"status":404`,
+		},
+		{
+			name:         "bulk delete",
+			method:       http.MethodPost,
+			path:         "/templates:batchDelete",
+			body:         `{"items":[{"id":"unknown","ifMatch":"\"etag\""}]}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `"status":404`,
+		},
+		{
+			name:         "unknown custom method",
+			method:       http.MethodPost,
+			path:         "/templates:unknown",
+			body:         `{}`,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "custom method without colon",
+			method:       http.MethodPost,
+			path:         "/templatesbatch",
+			body:         `{}`,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:          "custom method with another HTTP method",
+			method:        http.MethodGet,
+			path:          "/templates:batch",
+			expectedCode:  http.StatusMethodNotAllowed,
+			expectedAllow: "POST, OPTIONS",
+		},
+		{
+			name:          "custom method options",
+			method:        http.MethodOptions,
+			path:          "/templates:batch",
+			expectedCode:  http.StatusOK,
+			expectedAllow: "POST, OPTIONS",
+		},
+		{
+			name:          "collection options",
+			method:        http.MethodOptions,
+			path:          "/templates",
+			expectedCode:  http.StatusOK,
+			expectedAllow: "GET, HEAD, POST, OPTIONS",
+		},
+		{
+			name:         "collection",
+			method:       http.MethodGet,
+			path:         "/templates",
+			expectedCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			router := handlers.NewRouter(handlers.NewContext(&handlers.Config{
+				DBInMemory:        true,
+				IdempotencyKeyTTL: 24 * time.Hour,
+				OpenAPIValidation: true,
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+			router.ServeHTTP(w, r)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedBody) {
+				t.Errorf("expected %s in the body, got %s", tt.expectedBody, w.Body)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.expectedAllow {
+				t.Errorf("expected the methods %q to be allowed, got %q", tt.expectedAllow, allow)
+			}
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/denouche/go-api-skeleton/storage/dao"
 	"github.com/denouche/go-api-skeleton/storage/model"
 	"github.com/denouche/go-api-skeleton/storage/validators"
 	"github.com/denouche/go-api-skeleton/utils"
@@ -13,6 +12,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const entityTemplate = "template"
+
 // @openapi:path
 // /templates:
 //	get:
@@ -35,10 +36,9 @@ import (
 //						schema:
 //							$ref: "#/components/schemas/APIError"
 func (hc *Context) GetAllTemplates(c *gin.Context) {
-	templates, err := hc.db.GetAllTemplates()
+	templates, err := hc.getDB(c).GetAllTemplates()
 	if err != nil {
-		utils.GetLoggerFromCtx(c).WithError(err).Error("error while getting templates")
-		httputils.JSONErrorWithMessage(c.Writer, model.ErrInternalServer, "Error while getting templates")
+		_ = c.Error(err)
 		return
 	}
 	httputils.JSONOK(c, templates)
@@ -50,6 +50,13 @@ func (hc *Context) GetAllTemplates(c *gin.Context) {
 //		tags:
 //			- templates
 //		description: "Create a new template"
+//		parameters:
+//		- in: header
+//		  name: Idempotency-Key
+//		  schema:
+//		  	type: string
+//		  required: false
+//		  description: "A unique key generated by the client, making the request safe to retry. The response of the first request made with this key is replayed for the next ones."
 //		requestBody:
 //			description: The template data.
 //			required: true
@@ -70,8 +77,20 @@ func (hc *Context) GetAllTemplates(c *gin.Context) {
 //					application/json:
 //						schema:
 //							$ref: "#/components/schemas/APIError"
+//			403:
+//				description: "The request has been denied by an admission webhook"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
 //			409:
-//				description: "This error occurs when the new entity is in conflict with exiting one (duplicated)"
+//				description: "This error occurs when the new entity is in conflict with exiting one (duplicated), or when a request with the same idempotency key is in progress"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			422:
+//				description: "This error occurs when the idempotency key has already been used with another request"
 //				content:
 //					application/json:
 //						schema:
@@ -82,24 +101,44 @@ func (hc *Context) GetAllTemplates(c *gin.Context) {
 //					application/json:
 //						schema:
 //							$ref: "#/components/schemas/APIError"
+//			502:
+//				description: "An admission webhook cannot be called or answered badly"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
 func (hc *Context) CreateTemplate(c *gin.Context) {
 	body, err := c.GetRawData()
 	if err != nil {
-		utils.GetLoggerFromCtx(c).WithError(err).Error("error while creating template, read data fail")
-		httputils.JSONError(c.Writer, model.ErrInternalServer)
+		_ = c.Error(err)
 		return
 	}
 
 	templateToCreate := model.TemplateEditable{}
 	err = json.Unmarshal(body, &templateToCreate)
 	if err != nil {
-		httputils.JSONError(c.Writer, model.ErrBadRequestFormat)
+		httputils.JSONError(c, model.ErrBadRequestFormat)
 		return
 	}
 
-	err = hc.validator.StructCtx(validators.NewContextWithValidationContext(c, hc.db), templateToCreate)
+	validationCtx := validators.NewContextWithEntityValidationContext(c, hc.getDB(c), entityTemplate, "")
+	err = hc.validator.StructCtx(validationCtx, templateToCreate)
+	if errDB := validators.GetValidationContextError(validationCtx); errDB != nil {
+		_ = c.Error(errDB)
+		return
+	}
 	if err != nil {
-		httputils.JSONError(c.Writer, validators.NewDataValidationAPIError(err))
+		httputils.JSONError(c, validators.NewDataValidationAPIError(err))
+		return
+	}
+
+	if apiErr := validators.ValidateRules(entityTemplate, templateToCreate); apiErr != nil {
+		httputils.JSONError(c, *apiErr)
+		return
+	}
+
+	if apiErr := hc.admit(c, validationCtx, model.AdmissionOperationCreate, entityTemplate, &templateToCreate, nil); apiErr != nil {
+		httputils.JSONError(c, *apiErr)
 		return
 	}
 
@@ -107,20 +146,9 @@ func (hc *Context) CreateTemplate(c *gin.Context) {
 		TemplateEditable: templateToCreate,
 	}
 
-	err = hc.db.CreateTemplate(template)
-	if e, ok := err.(*dao.DAOError); ok {
-		switch {
-		case e.Type == dao.ErrTypeDuplicate:
-			httputils.JSONErrorWithMessage(c.Writer, model.ErrAlreadyExists, "Template already exists")
-			return
-		default:
-			utils.GetLoggerFromCtx(c).WithError(err).WithField("type", e.Type).Error("error CreateTemplate: Error type not handled")
-			httputils.JSONError(c.Writer, model.ErrInternalServer)
-			return
-		}
-	} else if err != nil {
-		utils.GetLoggerFromCtx(c).WithError(err).Error("error while creating template")
-		httputils.JSONError(c.Writer, model.ErrInternalServer)
+	err = hc.getDB(c).CreateTemplate(template)
+	if err != nil {
+		_ = c.Error(err).SetMeta("Template")
 		return
 	}
 
@@ -141,6 +169,18 @@ func (hc *Context) CreateTemplate(c *gin.Context) {
 //		  	type: string
 //		  required: true
 //		  description: "The template id to get"
+//		- in: header
+//		  name: If-None-Match
+//		  schema:
+//		  	type: string
+//		  required: false
+//		  description: "The template version known by the client, as given in the ETag response header. If the template has not been updated since, you will receive a 304 Not Modified response."
+//		- in: header
+//		  name: If-Modified-Since
+//		  schema:
+//		  	type: string
+//		  required: false
+//		  description: "The date of the template version known by the client, as given in the Last-Modified response header. Ignored when If-None-Match is given."
 //		responses:
 //			200:
 //				description: "The templates with id `templateID`"
@@ -148,6 +188,8 @@ func (hc *Context) CreateTemplate(c *gin.Context) {
 //					application/json:
 //						schema:
 //							$ref: "#/components/schemas/Template"
+//			304:
+//				description: "The template has not been modified since the version known by the client"
 //			404:
 //				description: "Template not found"
 //				content:
@@ -165,29 +207,18 @@ func (hc *Context) GetTemplate(c *gin.Context) {
 
 	err := hc.validator.VarCtx(c, templateID, "required")
 	if err != nil {
-		httputils.JSONError(c.Writer, validators.NewDataValidationAPIError(err))
+		httputils.JSONError(c, validators.NewDataValidationAPIError(err))
 		return
 	}
 
-	template, err := hc.db.GetTemplateByID(templateID)
-	if e, ok := err.(*dao.DAOError); ok {
-		switch {
-		case e.Type == dao.ErrTypeNotFound:
-			httputils.JSONErrorWithMessage(c.Writer, model.ErrNotFound, "Template not found")
-			return
-		default:
-			utils.GetLoggerFromCtx(c).WithError(err).WithField("type", e.Type).Error("error GetTemplate: get template error type not handled")
-			httputils.JSONError(c.Writer, model.ErrInternalServer)
-			return
-		}
-	} else if err != nil {
-		utils.GetLoggerFromCtx(c).WithError(err).Error("error while get template")
-		httputils.JSONError(c.Writer, model.ErrInternalServer)
+	template, err := hc.getDB(c).GetTemplateByID(templateID)
+	if err != nil {
+		_ = c.Error(err).SetMeta("Template")
 		return
 	}
 
 	if template == nil {
-		httputils.JSONErrorWithMessage(c.Writer, model.ErrNotFound, "Template not found")
+		httputils.JSONErrorWithMessage(c, model.ErrNotFound, "Template not found")
 		return
 	}
 
@@ -207,62 +238,87 @@ func (hc *Context) GetTemplate(c *gin.Context) {
 //		  	type: string
 //		  required: true
 //		  description: "The template id to delete"
+//		- in: header
+//		  name: If-Match
+//		  schema:
+//		  	type: string
+//		  required: false
+//		  description: "The template version to delete, as given in the ETag response header. Either If-Match or If-Unmodified-Since is required."
+//		- in: header
+//		  name: If-Unmodified-Since
+//		  schema:
+//		  	type: string
+//		  required: false
+//		  description: "The date of the template version to delete, as given in the Last-Modified response header. Ignored when If-Match is given."
 //		responses:
 //			204:
 //				description: "Templates with id `templateID` deleted"
+//			403:
+//				description: "The request has been denied by an admission webhook"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
 //			404:
 //				description: "Template not found"
 //				content:
 //					application/json:
 //						schema:
 //							$ref: "#/components/schemas/APIError"
+//			412:
+//				description: "The template has been modified since the version given in the precondition headers"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			428:
+//				description: "Neither If-Match nor If-Unmodified-Since header is given"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
 //			500:
 //				description: "Server error"
 //				content:
 //					application/json:
 //						schema:
 //							$ref: "#/components/schemas/APIError"
+//			502:
+//				description: "An admission webhook cannot be called or answered badly"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
 func (hc *Context) DeleteTemplate(c *gin.Context) {
 	templateID := c.Param("id")
 
 	err := hc.validator.VarCtx(c, templateID, "required")
 	if err != nil {
-		httputils.JSONError(c.Writer, validators.NewDataValidationAPIError(err))
+		httputils.JSONError(c, validators.NewDataValidationAPIError(err))
 		return
 	}
 
 	// check template id given in URL exists
-	_, err = hc.db.GetTemplateByID(templateID)
-	if e, ok := err.(*dao.DAOError); ok {
-		switch {
-		case e.Type == dao.ErrTypeNotFound:
-			httputils.JSONErrorWithMessage(c.Writer, model.ErrNotFound, "Template to delete not found")
-			return
-		default:
-			utils.GetLoggerFromCtx(c).WithError(err).WithField("type", e.Type).Error("error DeleteTemplate: get template error type not handled")
-			httputils.JSONError(c.Writer, model.ErrInternalServer)
-			return
-		}
-	} else if err != nil {
-		utils.GetLoggerFromCtx(c).WithError(err).Error("error while get template to delete")
-		httputils.JSONError(c.Writer, model.ErrInternalServer)
+	template, err := hc.getDB(c).GetTemplateByID(templateID)
+	if err != nil {
+		_ = c.Error(err).SetMeta("Template to delete")
 		return
 	}
 
-	err = hc.db.DeleteTemplate(templateID)
-	if e, ok := err.(*dao.DAOError); ok {
-		switch {
-		case e.Type == dao.ErrTypeNotFound:
-			httputils.JSONErrorWithMessage(c.Writer, model.ErrNotFound, "Template to delete not found")
-			return
-		default:
-			utils.GetLoggerFromCtx(c).WithError(err).WithField("type", e.Type).Error("error DeleteTemplate: Error type not handled")
-			httputils.JSONError(c.Writer, model.ErrInternalServer)
-			return
-		}
-	} else if err != nil {
-		utils.GetLoggerFromCtx(c).WithError(err).Error("error while deleting template")
-		httputils.JSONError(c.Writer, model.ErrInternalServer)
+	// check versions
+	if apiErr := httputils.CheckWritePreconditions(c, template); apiErr != nil {
+		httputils.JSONError(c, *apiErr)
+		return
+	}
+
+	if apiErr := hc.admit(c, nil, model.AdmissionOperationDelete, entityTemplate, nil, template); apiErr != nil {
+		httputils.JSONError(c, *apiErr)
+		return
+	}
+
+	err = hc.getDB(c).DeleteTemplate(templateID)
+	if err != nil {
+		_ = c.Error(err).SetMeta("Template to delete")
 		return
 	}
 
@@ -286,8 +342,14 @@ func (hc *Context) DeleteTemplate(c *gin.Context) {
 //		  name: If-Match
 //		  schema:
 //		  	type: string
-//		  required: true
-//		  description: "The template version to update. You can find the template version using the GET endpoint, in the ETag response header. If the version has been updated between your GET and your PUT, you will receive a 412 Precondition Failed response."
+//		  required: false
+//		  description: "The template version to update. You can find the template version using the GET endpoint, in the ETag response header. If the version has been updated between your GET and your PUT, you will receive a 412 Precondition Failed response. Either If-Match or If-Unmodified-Since is required."
+//		- in: header
+//		  name: If-Unmodified-Since
+//		  schema:
+//		  	type: string
+//		  required: false
+//		  description: "The date of the template version to update, as given in the Last-Modified response header. Ignored when If-Match is given."
 //		requestBody:
 //			description: The template data.
 //			required: true
@@ -308,91 +370,619 @@ func (hc *Context) DeleteTemplate(c *gin.Context) {
 //					application/json:
 //						schema:
 //							$ref: "#/components/schemas/APIError"
+//			403:
+//				description: "The request has been denied by an admission webhook"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
 //			404:
 //				description: "Template not found"
 //				content:
 //					application/json:
 //						schema:
 //							$ref: "#/components/schemas/APIError"
+//			412:
+//				description: "The template has been modified since the version given in the precondition headers"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			428:
+//				description: "Neither If-Match nor If-Unmodified-Since header is given"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
 //			500:
 //				description: "Server error"
 //				content:
 //					application/json:
 //						schema:
 //							$ref: "#/components/schemas/APIError"
+//			502:
+//				description: "An admission webhook cannot be called or answered badly"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
 func (hc *Context) UpdateTemplate(c *gin.Context) {
 	templateID := c.Param("id")
 
 	err := hc.validator.VarCtx(c, templateID, "required")
 	if err != nil {
-		httputils.JSONError(c.Writer, validators.NewDataValidationAPIError(err))
+		httputils.JSONError(c, validators.NewDataValidationAPIError(err))
 		return
 	}
 
 	// check template id given in URL exists
-	template, err := hc.db.GetTemplateByID(templateID)
-	if e, ok := err.(*dao.DAOError); ok {
-		switch {
-		case e.Type == dao.ErrTypeNotFound:
-			httputils.JSONErrorWithMessage(c.Writer, model.ErrNotFound, "Template to update not found")
-			return
-		default:
-			utils.GetLoggerFromCtx(c).WithError(err).WithField("type", e.Type).Error("UpdateTemplate: get template error type not handled")
-			httputils.JSONError(c.Writer, model.ErrInternalServer)
-			return
-		}
-	} else if err != nil {
-		utils.GetLoggerFromCtx(c).WithError(err).Error("error while get template to update")
-		httputils.JSONError(c.Writer, model.ErrInternalServer)
+	template, err := hc.getDB(c).GetTemplateByID(templateID)
+	if err != nil {
+		_ = c.Error(err).SetMeta("Template to update")
 		return
 	}
 
 	// check versions
-	if !utils.IsSameVersion(c.GetHeader(httputils.HeaderNameIfMatch), template) {
-		httputils.JSONError(c.Writer, model.ErrVersionMismatched)
+	if apiErr := httputils.CheckWritePreconditions(c, template); apiErr != nil {
+		httputils.JSONError(c, *apiErr)
 		return
 	}
 
 	// get body and verify data
 	body, err := c.GetRawData()
 	if err != nil {
-		utils.GetLoggerFromCtx(c).WithError(err).Error("error while updating template, read data fail")
-		httputils.JSONError(c.Writer, model.ErrInternalServer)
+		_ = c.Error(err)
 		return
 	}
 
 	templateToUpdate := model.TemplateEditable{}
 	err = json.Unmarshal(body, &templateToUpdate)
 	if err != nil {
-		httputils.JSONError(c.Writer, model.ErrBadRequestFormat)
+		httputils.JSONError(c, model.ErrBadRequestFormat)
 		return
 	}
 
-	err = hc.validator.StructCtx(validators.NewContextWithValidationContext(c, hc.db), templateToUpdate)
+	validationCtx := validators.NewContextWithEntityValidationContext(c, hc.getDB(c), entityTemplate, templateID)
+	err = hc.validator.StructCtx(validationCtx, templateToUpdate)
+	if errDB := validators.GetValidationContextError(validationCtx); errDB != nil {
+		_ = c.Error(errDB)
+		return
+	}
 	if err != nil {
-		httputils.JSONError(c.Writer, validators.NewDataValidationAPIError(err))
+		httputils.JSONError(c, validators.NewDataValidationAPIError(err))
+		return
+	}
+
+	if apiErr := validators.ValidateRules(entityTemplate, templateToUpdate); apiErr != nil {
+		httputils.JSONError(c, *apiErr)
+		return
+	}
+
+	if apiErr := hc.admit(c, validationCtx, model.AdmissionOperationUpdate, entityTemplate, &templateToUpdate, template); apiErr != nil {
+		httputils.JSONError(c, *apiErr)
 		return
 	}
 
 	template.TemplateEditable = templateToUpdate
 
 	// make the update
-	err = hc.db.UpdateTemplate(template)
-	if e, ok := err.(*dao.DAOError); ok {
-		switch {
-		case e.Type == dao.ErrTypeNotFound:
-			httputils.JSONErrorWithMessage(c.Writer, model.ErrNotFound, "Template to update not found")
-			return
-		default:
-			utils.GetLoggerFromCtx(c).WithError(err).WithField("type", e.Type).Error("error UpdateTemplate: Error type not handled")
-			httputils.JSONError(c.Writer, model.ErrInternalServer)
+	err = hc.getDB(c).UpdateTemplate(template)
+	if err != nil {
+		_ = c.Error(err).SetMeta("Template to update")
+		return
+	}
+
+	httputils.JSON(c.Writer, http.StatusOK, template)
+}
+
+// @openapi:path
+// /templates/{templateID}:
+//	patch:
+//		tags:
+//			- templates
+//		description: "Partially update a template, using a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) document"
+//		parameters:
+//		- in: path
+//		  name: templateID
+//		  schema:
+//		  	type: string
+//		  required: true
+//		  description: "The template id to update"
+//		- in: header
+//		  name: If-Match
+//		  schema:
+//		  	type: string
+//		  required: false
+//		  description: "The template version to update, as given in the ETag response header. Either If-Match or If-Unmodified-Since is required."
+//		- in: header
+//		  name: If-Unmodified-Since
+//		  schema:
+//		  	type: string
+//		  required: false
+//		  description: "The date of the template version to update, as given in the Last-Modified response header. Ignored when If-Match is given."
+//		requestBody:
+//			description: The patch to apply to the template data.
+//			required: true
+//			content:
+//				application/merge-patch+json:
+//					schema:
+//						$ref: "#/components/schemas/TemplateEditable"
+//				application/json-patch+json:
+//					schema:
+//						type: "array"
+//						items:
+//							$ref: "#/components/schemas/PatchOperation"
+//		responses:
+//			200:
+//				description: "The updated template"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/Template"
+//			400:
+//				description: "This error occurs when the request is not correct (bad patch format, validation error)"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			403:
+//				description: "The request has been denied by an admission webhook"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			404:
+//				description: "Template not found"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			409:
+//				description: "The patch cannot be applied to the template (failed test operation, unknown path)"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			412:
+//				description: "The template has been modified since the version given in the precondition headers"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			415:
+//				description: "The patch format is not supported"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			428:
+//				description: "Neither If-Match nor If-Unmodified-Since header is given"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			500:
+//				description: "Server error"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			502:
+//				description: "An admission webhook cannot be called or answered badly"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+func (hc *Context) PatchTemplate(c *gin.Context) {
+	templateID := c.Param("id")
+
+	err := hc.validator.VarCtx(c, templateID, "required")
+	if err != nil {
+		httputils.JSONError(c, validators.NewDataValidationAPIError(err))
+		return
+	}
+
+	// check template id given in URL exists
+	template, err := hc.getDB(c).GetTemplateByID(templateID)
+	if err != nil {
+		_ = c.Error(err).SetMeta("Template to patch")
+		return
+	}
+
+	// check versions
+	if apiErr := httputils.CheckWritePreconditions(c, template); apiErr != nil {
+		httputils.JSONError(c, *apiErr)
+		return
+	}
+
+	// get body and apply the patch to the current template data
+	body, err := c.GetRawData()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	original, err := json.Marshal(template.TemplateEditable)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	patched, apiErr := httputils.ApplyPatch(c.ContentType(), original, body)
+	if apiErr != nil {
+		httputils.JSONError(c, *apiErr)
+		return
+	}
+
+	templateToUpdate := model.TemplateEditable{}
+	err = json.Unmarshal(patched, &templateToUpdate)
+	if err != nil {
+		httputils.JSONError(c, model.ErrBadRequestFormat)
+		return
+	}
+
+	validationCtx := validators.NewContextWithEntityValidationContext(c, hc.getDB(c), entityTemplate, templateID)
+	err = hc.validator.StructCtx(validationCtx, templateToUpdate)
+	if errDB := validators.GetValidationContextError(validationCtx); errDB != nil {
+		_ = c.Error(errDB)
+		return
+	}
+	if err != nil {
+		httputils.JSONError(c, validators.NewDataValidationAPIError(err))
+		return
+	}
+
+	if apiErr := validators.ValidateRules(entityTemplate, templateToUpdate); apiErr != nil {
+		httputils.JSONError(c, *apiErr)
+		return
+	}
+
+	if apiErr := hc.admit(c, validationCtx, model.AdmissionOperationUpdate, entityTemplate, &templateToUpdate, template); apiErr != nil {
+		httputils.JSONError(c, *apiErr)
+		return
+	}
+
+	template.TemplateEditable = templateToUpdate
+
+	// make the update
+	err = hc.getDB(c).UpdateTemplate(template)
+	if err != nil {
+		_ = c.Error(err).SetMeta("Template to patch")
+		return
+	}
+
+	httputils.JSON(c.Writer, http.StatusOK, template)
+}
+
+// @openapi:path
+// /templates:batch:
+//	post:
+//		tags:
+//			- templates
+//		description: "Create several templates. In all_or_nothing mode (the default), no template is created if any of them fails."
+//		requestBody:
+//			description: The templates data.
+//			required: true
+//			content:
+//				application/json:
+//					schema:
+//						$ref: "#/components/schemas/TemplateBatchCreateRequest"
+//		responses:
+//			200:
+//				description: "The result of each item, in the same order as in the request, with the created template or the error"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/BatchResponse"
+//			400:
+//				description: "This error occurs when the request is not correct (bad body format, bad mode)"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			413:
+//				description: "This error occurs when the request body is too large, or the batch has too many items"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			500:
+//				description: "Server error"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+func (hc *Context) CreateTemplates(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	request := model.TemplateBatchCreateRequest{}
+	err = json.Unmarshal(body, &request)
+	if err != nil {
+		httputils.JSONError(c, model.ErrBadRequestFormat)
+		return
+	}
+	if isBatchTooLarge(len(request.Items), hc.config.BatchMaxItems) {
+		httputils.JSONError(c, newBatchTooLargeError(keyBatchTooManyItems, hc.config.BatchMaxItems))
+		return
+	}
+
+	validationCtx := validators.NewContextWithEntityValidationContext(c, hc.getDB(c), entityTemplate, "")
+	err = hc.validator.StructCtx(validationCtx, request)
+	if err != nil {
+		httputils.JSONError(c, validators.NewDataValidationAPIError(err))
+		return
+	}
+
+	atomic := isAtomicBatch(request.Mode)
+	results := newBatchResults(len(request.Items))
+	templates := make([]*model.Template, 0, len(request.Items))
+	indexes := make([]int, 0, len(request.Items))
+	for i, item := range request.Items {
+		err = hc.validator.StructCtx(validationCtx, item)
+		if err != nil {
+			results.setError(i, validators.NewDataValidationAPIError(err))
+			continue
+		}
+		if apiErr := validators.ValidateRules(entityTemplate, item); apiErr != nil {
+			results.setError(i, *apiErr)
+			continue
+		}
+		if apiErr := hc.admit(c, validationCtx, model.AdmissionOperationCreate, entityTemplate, &item, nil); apiErr != nil {
+			results.setError(i, *apiErr)
+			continue
+		}
+		templates = append(templates, &model.Template{TemplateEditable: item})
+		indexes = append(indexes, i)
+	}
+	if errDB := validators.GetValidationContextError(validationCtx); errDB != nil {
+		_ = c.Error(errDB)
+		return
+	}
+
+	if !atomic || !results.failed() {
+		errs, err := hc.getDB(c).CreateTemplates(templates, atomic)
+		results.setDAOResults(c, indexes, errs, err, http.StatusCreated, func(j int) interface{} {
+			return templates[j]
+		})
+	}
+
+	if atomic && results.failed() {
+		results.abort()
+	}
+	httputils.JSON(c.Writer, http.StatusOK, results.response())
+}
+
+// @openapi:path
+// /templates:batchUpdate:
+//	post:
+//		tags:
+//			- templates
+//		description: "Update several templates. In all_or_nothing mode (the default), no template is updated if any of them fails."
+//		requestBody:
+//			description: The templates data. The ifMatch of each item is required and checked against the template version like the If-Match header.
+//			required: true
+//			content:
+//				application/json:
+//					schema:
+//						$ref: "#/components/schemas/TemplateBatchUpdateRequest"
+//		responses:
+//			200:
+//				description: "The result of each item, in the same order as in the request, with the updated template or the error"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/BatchResponse"
+//			400:
+//				description: "This error occurs when the request is not correct (bad body format, bad mode)"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			413:
+//				description: "This error occurs when the request body is too large, or the batch has too many items"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			500:
+//				description: "Server error"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+func (hc *Context) UpdateTemplates(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	request := model.TemplateBatchUpdateRequest{}
+	err = json.Unmarshal(body, &request)
+	if err != nil {
+		httputils.JSONError(c, model.ErrBadRequestFormat)
+		return
+	}
+	if isBatchTooLarge(len(request.Items), hc.config.BatchMaxItems) {
+		httputils.JSONError(c, newBatchTooLargeError(keyBatchTooManyItems, hc.config.BatchMaxItems))
+		return
+	}
+
+	validationCtx := validators.NewContextWithValidationContext(c, hc.getDB(c))
+	err = hc.validator.StructCtx(validationCtx, request)
+	if err != nil {
+		httputils.JSONError(c, validators.NewDataValidationAPIError(err))
+		return
+	}
+
+	atomic := isAtomicBatch(request.Mode)
+	results := newBatchResults(len(request.Items))
+	templates := make([]*model.Template, 0, len(request.Items))
+	indexes := make([]int, 0, len(request.Items))
+	for i, item := range request.Items {
+		itemValidationCtx := validators.NewContextWithEntityValidationContext(c, hc.getDB(c), entityTemplate, item.ID)
+		err = hc.validator.StructCtx(itemValidationCtx, item)
+		if errDB := validators.GetValidationContextError(itemValidationCtx); errDB != nil {
+			_ = c.Error(errDB)
 			return
 		}
-	} else if err != nil {
-		utils.GetLoggerFromCtx(c).WithError(err).Error("error while updating template")
-		httputils.JSONError(c.Writer, model.ErrInternalServer)
+		if err != nil {
+			results.setError(i, validators.NewDataValidationAPIError(err))
+			continue
+		}
+		if apiErr := validators.ValidateRules(entityTemplate, item.Data); apiErr != nil {
+			results.setError(i, *apiErr)
+			continue
+		}
+
+		if item.IfMatch == "" {
+			// like the If-Match header of the single updates
+			results.setError(i, errBatchItemPreconditionRequired)
+			continue
+		}
+		template, err := hc.getDB(c).GetTemplateByID(item.ID)
+		if err != nil {
+			results.setError(i, batchItemDAOError(c, err))
+			continue
+		}
+		if !utils.IsSameVersion(item.IfMatch, template) {
+			// check versions
+			results.setError(i, model.ErrVersionMismatched)
+			continue
+		}
+		if apiErr := hc.admit(c, itemValidationCtx, model.AdmissionOperationUpdate, entityTemplate, &item.Data, template); apiErr != nil {
+			results.setError(i, *apiErr)
+			continue
+		}
+
+		templates = append(templates, &model.Template{ID: item.ID, TemplateEditable: item.Data})
+		indexes = append(indexes, i)
+	}
+
+	if !atomic || !results.failed() {
+		errs, err := hc.getDB(c).UpdateTemplates(templates, atomic)
+		results.setDAOResults(c, indexes, errs, err, http.StatusOK, func(j int) interface{} {
+			return templates[j]
+		})
+	}
+
+	if atomic && results.failed() {
+		results.abort()
+	}
+	httputils.JSON(c.Writer, http.StatusOK, results.response())
+}
+
+// @openapi:path
+// /templates:batchDelete:
+//	post:
+//		tags:
+//			- templates
+//		description: "Delete several templates. In all_or_nothing mode (the default), no template is deleted if any of them fails."
+//		requestBody:
+//			description: The ids of the templates to delete. The ifMatch of each item is required and checked against the template version like the If-Match header.
+//			required: true
+//			content:
+//				application/json:
+//					schema:
+//						$ref: "#/components/schemas/BatchDeleteRequest"
+//		responses:
+//			200:
+//				description: "The result of each item, in the same order as in the request"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/BatchResponse"
+//			400:
+//				description: "This error occurs when the request is not correct (bad body format, bad mode)"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			413:
+//				description: "This error occurs when the request body is too large, or the batch has too many items"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+//			500:
+//				description: "Server error"
+//				content:
+//					application/json:
+//						schema:
+//							$ref: "#/components/schemas/APIError"
+func (hc *Context) DeleteTemplates(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
-	httputils.JSON(c.Writer, http.StatusOK, template)
+	request := model.BatchDeleteRequest{}
+	err = json.Unmarshal(body, &request)
+	if err != nil {
+		httputils.JSONError(c, model.ErrBadRequestFormat)
+		return
+	}
+	if isBatchTooLarge(len(request.Items), hc.config.BatchMaxItems) {
+		httputils.JSONError(c, newBatchTooLargeError(keyBatchTooManyItems, hc.config.BatchMaxItems))
+		return
+	}
+
+	validationCtx := validators.NewContextWithValidationContext(c, hc.getDB(c))
+	err = hc.validator.StructCtx(validationCtx, request)
+	if err != nil {
+		httputils.JSONError(c, validators.NewDataValidationAPIError(err))
+		return
+	}
+
+	atomic := isAtomicBatch(request.Mode)
+	results := newBatchResults(len(request.Items))
+	ids := make([]string, 0, len(request.Items))
+	indexes := make([]int, 0, len(request.Items))
+	for i, item := range request.Items {
+		err = hc.validator.StructCtx(validationCtx, item)
+		if err != nil {
+			results.setError(i, validators.NewDataValidationAPIError(err))
+			continue
+		}
+
+		if item.IfMatch == "" {
+			// like the If-Match header of the single deletions
+			results.setError(i, errBatchItemPreconditionRequired)
+			continue
+		}
+		template, err := hc.getDB(c).GetTemplateByID(item.ID)
+		if err != nil {
+			results.setError(i, batchItemDAOError(c, err))
+			continue
+		}
+		if !utils.IsSameVersion(item.IfMatch, template) {
+			// check versions
+			results.setError(i, model.ErrVersionMismatched)
+			continue
+		}
+		if apiErr := hc.admit(c, nil, model.AdmissionOperationDelete, entityTemplate, nil, template); apiErr != nil {
+			results.setError(i, *apiErr)
+			continue
+		}
+
+		ids = append(ids, item.ID)
+		indexes = append(indexes, i)
+	}
+
+	if !atomic || !results.failed() {
+		errs, err := hc.getDB(c).DeleteTemplates(ids, atomic)
+		results.setDAOResults(c, indexes, errs, err, http.StatusNoContent, func(j int) interface{} {
+			return nil
+		})
+	}
+
+	if atomic && results.failed() {
+		results.abort()
+	}
+	httputils.JSON(c.Writer, http.StatusOK, results.response())
 }
@@ -0,0 +1,103 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/handlers"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
+	"github.com/denouche/go-api-skeleton/utils/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanID  = "00f067aa0ba902b7"
+)
+
+func TestTracing(t *testing.T) {
+	utils.InitLogger("error", utils.LogFormatText)
+	if err := i18n.Init("", "en"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		traceparent string
+	}{
+		{name: "with a trace context", traceparent: "00-" + parentTraceID + "-" + parentSpanID + "-01"},
+		{name: "without trace context"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			previous := otel.GetTracerProvider()
+			shutdown := tracing.InitWithExporter(tracing.Config{SampleRatio: 1, ServiceName: "test"}, exporter)
+			t.Cleanup(func() {
+				_ = shutdown(context.Background())
+				otel.SetTracerProvider(previous)
+			})
+			router := handlers.NewRouter(handlers.NewContext(&handlers.Config{
+				DBInMemory:        true,
+				IdempotencyKeyTTL: 24 * time.Hour,
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/templates", strings.NewReader(`{"name":"foo"}`))
+			r.Header.Set("Content-Type", "application/json")
+			if tt.traceparent != "" {
+				r.Header.Set("traceparent", tt.traceparent)
+			}
+			router.ServeHTTP(w, r)
+			if w.Code != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body)
+			}
+
+			var server tracetest.SpanStub
+			var daoSpans []tracetest.SpanStub
+			for _, span := range exporter.GetSpans() {
+				switch {
+				case span.SpanKind == trace.SpanKindServer:
+					server = span
+				case strings.HasPrefix(span.Name, "dao "):
+					daoSpans = append(daoSpans, span)
+				}
+			}
+			if server.Name != "POST /templates" {
+				t.Fatalf("no span of the request, got %+v", exporter.GetSpans())
+			}
+
+			traceID := server.SpanContext.TraceID().String()
+			if tt.traceparent != "" {
+				if traceID != parentTraceID || server.Parent.SpanID().String() != parentSpanID || !server.Parent.IsRemote() {
+					t.Errorf("the span of the request is not a child of the traceparent, got trace %s and parent %s",
+						traceID, server.Parent.SpanID())
+				}
+			} else if server.Parent.IsValid() {
+				t.Errorf("the span of the request has a parent %s", server.Parent.SpanID())
+			}
+
+			if correlationID := w.Header().Get(httputils.HeaderNameCorrelationID); correlationID != traceID {
+				t.Errorf("expected the trace ID %s as correlationID, got %s", traceID, correlationID)
+			}
+
+			if len(daoSpans) == 0 {
+				t.Fatal("no span of the database calls")
+			}
+			for _, span := range daoSpans {
+				if span.Parent.SpanID() != server.SpanContext.SpanID() || span.SpanContext.TraceID() != server.SpanContext.TraceID() {
+					t.Errorf("the span %s is not a child of the span of the request", span.Name)
+				}
+			}
+		})
+	}
+}
@@ -2,7 +2,7 @@ package main
 
 import "github.com/denouche/go-api-skeleton/cmd"
 
-//go:generate go run scripts/includeopenapi.go
+//go:generate go run main.go openapi generate
 //go:generate scripts/copy-models-to-client.sh
 
 func main() {
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRequestBodySizeMiddleware rejects the requests whose body is larger than max bytes with a request too large error.
+// The body of the requests sent without Content-Length fails to be read past max bytes. A max of 0 disables the limit.
+func GetRequestBodySizeMiddleware(max int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if max <= 0 {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > max {
+			httputils.JSONError(c, model.ErrRequestTooLarge)
+			c.Abort()
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, max)
+		c.Next()
+	}
+}
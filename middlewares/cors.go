@@ -2,20 +2,181 @@ package middlewares
 
 import (
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/denouche/go-api-skeleton/utils"
 	"github.com/denouche/go-api-skeleton/utils/httputils"
 	"github.com/gin-gonic/gin"
 )
 
-func GetCORSMiddlewareForOthersHTTPMethods() gin.HandlerFunc {
+const (
+	corsAllOrigins         = "*"
+	corsRegexpOriginPrefix = "regexp:"
+)
+
+// CORSConfig describes the cross origin resource sharing policy of the api.
+// AllowedOrigins entries can be an exact origin (https://foo.example.com), a wildcard
+// pattern (https://*.example.com), a regular expression prefixed by "regexp:", or "*" to allow any origin.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	MaxAge           time.Duration
+	AllowCredentials bool
+}
+
+type CORSPolicy struct {
+	config         CORSConfig
+	allowAll       bool
+	originMatchers []*regexp.Regexp
+}
+
+func NewCORSPolicy(config CORSConfig) *CORSPolicy {
+	p := &CORSPolicy{
+		config: config,
+	}
+	for _, origin := range config.AllowedOrigins {
+		origin = strings.TrimSpace(origin)
+		switch {
+		case origin == "":
+			continue
+		case origin == corsAllOrigins:
+			p.allowAll = true
+		case strings.HasPrefix(origin, corsRegexpOriginPrefix):
+			re, err := regexp.Compile(strings.TrimPrefix(origin, corsRegexpOriginPrefix))
+			if err != nil {
+				utils.GetComponentLogger(utils.LoggerComponentHTTP).WithError(err).WithField("origin", origin).Error("invalid CORS allowed origin regexp, ignoring it")
+				continue
+			}
+			p.originMatchers = append(p.originMatchers, re)
+		default:
+			// exact origins and wildcard patterns are both turned into anchored regexps
+			pattern := "^" + strings.Replace(regexp.QuoteMeta(strings.ToLower(origin)), `\*`, `[^/]*`, -1) + "$"
+			p.originMatchers = append(p.originMatchers, regexp.MustCompile(pattern))
+		}
+	}
+	return p
+}
+
+// IsOriginAllowed returns true if the given origin is allowed by the policy
+func (p *CORSPolicy) IsOriginAllowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	if p.allowAll {
+		return true
+	}
+	for _, re := range p.originMatchers {
+		if re.MatchString(strings.ToLower(origin)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedMethods returns the methods allowed for a resource supporting resourceMethods.
+// When no methods are configured in the policy, all the resource methods are allowed.
+func (p *CORSPolicy) AllowedMethods(resourceMethods ...string) []string {
+	result := make([]string, 0, len(resourceMethods)+1)
+	for _, method := range resourceMethods {
+		if len(p.config.AllowedMethods) == 0 || containsFold(p.config.AllowedMethods, method) {
+			result = append(result, method)
+		}
+	}
+	if !containsFold(result, http.MethodOptions) {
+		result = append(result, http.MethodOptions)
+	}
+	return result
+}
+
+// AreHeadersAllowed checks the headers listed in an Access-Control-Request-Headers header value
+func (p *CORSPolicy) AreHeadersAllowed(requestHeaders string) bool {
+	for _, h := range strings.Split(requestHeaders, ",") {
+		h = strings.TrimSpace(h)
+		if h != "" && !containsFold(p.allowedHeaders(), h) {
+			return false
+		}
+	}
+	return true
+}
+
+// SetPreflightHeaders sets the CORS headers of a preflight response
+func (p *CORSPolicy) SetPreflightHeaders(w http.ResponseWriter, origin string, allowedMethods []string) {
+	p.setOriginHeaders(w, origin)
+	w.Header().Set(httputils.HeaderNameAccessControlAllowMethods, strings.Join(allowedMethods, ","))
+	w.Header().Set(httputils.HeaderNameAccessControlAllowHeaders, strings.Join(p.allowedHeaders(), ","))
+	if p.config.MaxAge > 0 {
+		w.Header().Set(httputils.HeaderNameAccessControlMaxAge, strconv.Itoa(int(p.config.MaxAge.Seconds())))
+	}
+}
+
+// SetActualRequestHeaders sets the CORS headers of a response to a non preflight request
+func (p *CORSPolicy) SetActualRequestHeaders(w http.ResponseWriter, origin string) {
+	p.setOriginHeaders(w, origin)
+	if len(p.config.ExposedHeaders) > 0 {
+		w.Header().Set(httputils.HeaderNameAccessControlExposeHeaders, strings.Join(p.config.ExposedHeaders, ","))
+	}
+}
+
+// SetVaryHeader tells the caches that the responses depend on the request origin, when they do. It is set whatever the
+// request origin, a response without CORS headers must not be served to an allowed origin, nor the reverse.
+func (p *CORSPolicy) SetVaryHeader(w http.ResponseWriter) {
+	if p.variesByOrigin() {
+		w.Header().Add(httputils.HeaderNameVary, httputils.HeaderNameOrigin)
+	}
+}
+
+// variesByOrigin returns false when the CORS headers are the same for all the requests: when no origin is allowed,
+// or all of them without credentials
+func (p *CORSPolicy) variesByOrigin() bool {
+	if p.allowAll {
+		return p.config.AllowCredentials
+	}
+	return len(p.originMatchers) > 0
+}
+
+func (p *CORSPolicy) setOriginHeaders(w http.ResponseWriter, origin string) {
+	if p.allowAll && !p.config.AllowCredentials {
+		w.Header().Set(httputils.HeaderNameAccessControlAllowOrigin, corsAllOrigins)
+		return
+	}
+
+	w.Header().Set(httputils.HeaderNameAccessControlAllowOrigin, origin)
+	if p.config.AllowCredentials {
+		w.Header().Set(httputils.HeaderNameAccessControlAllowCredentials, "true")
+	}
+}
+
+func (p *CORSPolicy) allowedHeaders() []string {
+	if len(p.config.AllowedHeaders) == 0 {
+		return httputils.AllowedHeaders
+	}
+	return p.config.AllowedHeaders
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func GetCORSMiddlewareForOthersHTTPMethods(policy *CORSPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request.Method == http.MethodOptions {
 			c.Next()
 			return
 		}
 
-		if v := c.Request.Header.Get(httputils.HeaderNameOrigin); v != "" {
-			c.Writer.Header().Set(httputils.HeaderNameAccessControlAllowOrigin, v)
+		policy.SetVaryHeader(c.Writer)
+		if v := c.Request.Header.Get(httputils.HeaderNameOrigin); v != "" && policy.IsOriginAllowed(v) {
+			policy.SetActualRequestHeaders(c.Writer, v)
 		}
 		c.Next()
 	}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/denouche/go-api-skeleton/storage/dao"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetErrorMiddleware sends the response of the requests whose handler gave up with c.Error(err).
+// The meta of the error, if it is a string, is used as the subject of the error message ("Template to update").
+// The error is logged here, once: as an error for server errors, as a debug message otherwise.
+func GetErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		renderErrors(c)
+	}
+}
+
+// renderErrors sends the last error added to the context, unless a response has already been written
+func renderErrors(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	ginErr := c.Errors.Last()
+	subject, _ := ginErr.Meta.(string)
+	apiErr := httputils.NewAPIErrorFromError(ginErr.Err, subject)
+
+	logEntry := utils.GetLoggerFromCtx(c).WithError(ginErr.Err).WithField("type", apiErr.Type)
+	var daoErr *dao.DAOError
+	if errors.As(ginErr.Err, &daoErr) {
+		logEntry = logEntry.WithField("dao_error_type", daoErr.Type.String())
+	}
+	if apiErr.HTTPCode >= http.StatusInternalServerError {
+		logEntry.Errorf("error while processing %s %s", c.Request.Method, c.FullPath())
+	} else {
+		logEntry.Debugf("error while processing %s %s", c.Request.Method, c.FullPath())
+	}
+
+	httputils.JSONError(c, apiErr)
+}
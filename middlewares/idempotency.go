@@ -0,0 +1,185 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/storage/dao"
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyResponseWriter keeps a copy of the response body, to be able to store it
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func getRequestFingerprint(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+	// the same key used by another client must not replay the response of the first one
+	h.Write([]byte(c.GetHeader(httputils.HeaderNameAuthorization) + "\n"))
+	h.Write(body)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// GetIdempotencyMiddleware makes the POST requests given with an Idempotency-Key header safe to retry.
+// The first response is stored for ttl and replayed for the next requests with the same key.
+// The records are stored in the database given by getDB for the request, the transaction of a batch operation if any.
+func GetIdempotencyMiddleware(getDB func(c *gin.Context) dao.Database, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(httputils.HeaderNameIdempotencyKey)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		db := getDB(c)
+
+		body, err := c.GetRawData()
+		if err != nil {
+			// e.g. a body larger than the maximum size
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		now := time.Now()
+		record := &model.IdempotencyRecord{
+			Key:         key,
+			Fingerprint: getRequestFingerprint(c, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+
+		err = db.CreateIdempotencyRecord(record)
+		if errors.Is(err, dao.ErrDuplicate) {
+			replayIdempotentResponse(c, db, record)
+			c.Abort()
+			return
+		} else if err != nil {
+			utils.GetLoggerFromCtx(c).WithError(err).Error("error while creating idempotency record")
+			httputils.JSONError(c, model.ErrInternalServer)
+			c.Abort()
+			return
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				// the request failed, let the client retry it with the same key once the panic is recovered
+				if err := db.DeleteIdempotencyRecord(key); err != nil {
+					utils.GetLoggerFromCtx(c).WithError(err).Error("error while deleting idempotency record")
+				}
+				panic(r)
+			}
+		}()
+
+		w := &idempotencyResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		// the errors must be sent before saving the response
+		renderErrors(c)
+
+		if w.Status() >= http.StatusInternalServerError {
+			// the request failed, let the client retry it with the same key
+			err = db.DeleteIdempotencyRecord(key)
+			if err != nil {
+				utils.GetLoggerFromCtx(c).WithError(err).Error("error while deleting idempotency record")
+			}
+			return
+		}
+
+		record.Completed = true
+		record.ResponseStatus = w.Status()
+		record.ResponseHeaders = replayableHeaders(w.Header())
+		record.ResponseBody = w.body.Bytes()
+		err = db.UpdateIdempotencyRecord(record)
+		if err != nil {
+			utils.GetLoggerFromCtx(c).WithError(err).Error("error while saving idempotency record")
+		}
+	}
+}
+
+func replayIdempotentResponse(c *gin.Context, db dao.Database, record *model.IdempotencyRecord) {
+	existing, err := db.GetIdempotencyRecord(record.Key)
+	if errors.Is(err, dao.ErrNotFound) {
+		// the record expired or its request failed in the meantime
+		httputils.JSONError(c, model.ErrIdempotencyRequestInProgress)
+		return
+	} else if err != nil {
+		utils.GetLoggerFromCtx(c).WithError(err).Error("error while getting idempotency record")
+		httputils.JSONError(c, model.ErrInternalServer)
+		return
+	}
+
+	if existing.Fingerprint != record.Fingerprint {
+		httputils.JSONError(c, model.ErrIdempotencyKeyReused)
+		return
+	}
+
+	if !existing.Completed {
+		httputils.JSONError(c, model.ErrIdempotencyRequestInProgress)
+		return
+	}
+
+	// the headers already set by the middlewares, like the CORS ones, are replaced and not duplicated
+	for k, values := range replayableHeaders(existing.ResponseHeaders) {
+		c.Writer.Header().Del(k)
+		for _, v := range values {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	c.Writer.Header().Set(httputils.HeaderNameIdempotentReplayed, "true")
+	c.Writer.WriteHeader(existing.ResponseStatus)
+	_, _ = c.Writer.Write(existing.ResponseBody)
+}
+
+// notReplayedHeaders are the response headers specific to a request: its correlationID, the CORS headers
+// depending on its origin, and the hop-by-hop headers
+var notReplayedHeaders = map[string]bool{
+	http.CanonicalHeaderKey(httputils.HeaderNameCorrelationID):                 true,
+	http.CanonicalHeaderKey(httputils.HeaderNameVary):                          true,
+	http.CanonicalHeaderKey(httputils.HeaderNameAccessControlAllowOrigin):      true,
+	http.CanonicalHeaderKey(httputils.HeaderNameAccessControlAllowCredentials): true,
+	http.CanonicalHeaderKey(httputils.HeaderNameAccessControlExposeHeaders):    true,
+	http.CanonicalHeaderKey(httputils.HeaderNameIdempotentReplayed):            true,
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+}
+
+// replayableHeaders returns the headers of a response which can be sent again to another request
+func replayableHeaders(header http.Header) http.Header {
+	result := http.Header{}
+	for k, values := range header {
+		k = http.CanonicalHeaderKey(k)
+		if notReplayedHeaders[k] {
+			continue
+		}
+		result[k] = append(result[k], values...)
+	}
+	return result
+}
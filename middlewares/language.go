@@ -0,0 +1,19 @@
+package middlewares
+
+import (
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// GetLanguageMiddleware negotiates the language of the messages from the Accept-Language header,
+// and announces it in the Content-Language response header
+func GetLanguageMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := i18n.Negotiate(c.Request.Header.Get(httputils.HeaderNameAcceptLanguage))
+		c.Set(i18n.ContextKeyLanguage, lang)
+		c.Writer.Header().Set(httputils.HeaderNameContentLanguage, lang)
+		c.Writer.Header().Add(httputils.HeaderNameVary, httputils.HeaderNameAcceptLanguage)
+		c.Next()
+	}
+}
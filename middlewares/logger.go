@@ -1,53 +1,35 @@
 package middlewares
 
 import (
-	"math/rand"
 	"time"
 
 	"github.com/denouche/go-api-skeleton/utils"
 	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/denouche/go-api-skeleton/utils/tracing"
 	"github.com/gin-gonic/gin"
 )
 
-const (
-	letterBytes   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	letterIdxBits = 6                    // 6 bits to represent a letter index
-	letterIdxMask = 1<<letterIdxBits - 1 // All 1-bits, as many as letterIdxBits
-	letterIdxMax  = 63 / letterIdxBits   // # of letter indices fitting in 63 bits
-)
-
-var src = rand.NewSource(time.Now().UnixNano())
-
-func randStringBytesMaskImprSrc(n int) string {
-	b := make([]byte, n)
-	// A src.Int63() generates 63 random bits, enough for letterIdxMax characters!
-	for i, cache, remain := n-1, src.Int63(), letterIdxMax; i >= 0; {
-		if remain == 0 {
-			cache, remain = src.Int63(), letterIdxMax
-		}
-		if idx := int(cache & letterIdxMask); idx < len(letterBytes) {
-			b[i] = letterBytes[idx]
-			i--
-		}
-		cache >>= letterIdxBits
-		remain--
-	}
-
-	return string(b)
-}
+const logFieldTraceID = "traceID"
 
+// GetLoggerMiddleware sets the logger of the request, with its correlationID: the one given in the request header,
+// or the ID of its trace, set in the response header
 func GetLoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		correlationID := c.Request.Header.Get(httputils.HeaderNameCorrelationID)
 		if correlationID == "" {
-			correlationID = randStringBytesMaskImprSrc(30)
+			correlationID = tracing.TraceID(c.Request.Context())
 			c.Writer.Header().Set(httputils.HeaderNameCorrelationID, correlationID)
 		}
 
-		logger := utils.GetLogger()
+		logger := utils.GetComponentLogger(utils.LoggerComponentHTTP)
 		logEntry := logger.WithField(httputils.HeaderNameCorrelationID, correlationID)
+		if ctx := c.Request.Context(); tracing.IsTraced(ctx) && tracing.TraceID(ctx) != correlationID {
+			// the correlationID given by the client does not identify the trace
+			logEntry = logEntry.WithField(logFieldTraceID, tracing.TraceID(ctx))
+		}
 
 		c.Set(utils.ContextKeyLogger, logEntry)
+		c.Set(utils.ContextKeyCorrelationID, correlationID)
 	}
 }
 
@@ -0,0 +1,278 @@
+package middlewares
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	// the merge patches are JSON documents, validate them against their schema as such
+	openapi3filter.RegisterBodyDecoder(httputils.HeaderValueApplicationMergePatchJSON, openapi3filter.JSONBodyDecoder)
+}
+
+type OpenAPIValidatorConfig struct {
+	// ValidateRequests rejects the requests not matching the document with a data validation error
+	ValidateRequests bool
+	// ValidateResponses replaces the responses not matching the document with a server error, it is meant for tests
+	// as the responses are buffered to be checked
+	ValidateResponses bool
+}
+
+// OpenAPIValidator checks the requests and the responses of the routes described in an OpenAPI document
+type OpenAPIValidator struct {
+	config OpenAPIValidatorConfig
+	router routers.Router
+}
+
+// NewOpenAPIValidator parses and validates the OpenAPI document, and builds the router matching the requests with its operations
+func NewOpenAPIValidator(document []byte, config OpenAPIValidatorConfig) (*OpenAPIValidator, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(document)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the openapi document: %w", err)
+	}
+	err = doc.Validate(loader.Context)
+	if err != nil {
+		return nil, fmt.Errorf("invalid openapi document: %w", err)
+	}
+
+	// the servers are the URLs the clients use to reach the api, maybe through a proxy: only the paths are matched
+	doc.Servers = nil
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build the openapi router: %w", err)
+	}
+
+	return &OpenAPIValidator{
+		config: config,
+		router: router,
+	}, nil
+}
+
+// openAPIResponseWriter buffers the response, to be able to replace it when it does not match the document
+type openAPIResponseWriter struct {
+	gin.ResponseWriter
+	body    bytes.Buffer
+	written bool
+}
+
+func (w *openAPIResponseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *openAPIResponseWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *openAPIResponseWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *openAPIResponseWriter) Written() bool {
+	return w.written
+}
+
+// GetOpenAPIValidationMiddleware validates the path params, query params, headers and body of the requests against
+// the operation of the OpenAPI document they match. The requests not described by the document are not checked.
+func GetOpenAPIValidationMiddleware(v *OpenAPIValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, pathParams, err := v.router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		requestInput := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError: true,
+				// the handlers set the default values themselves, the body must be given to them as sent
+				SkipSettingDefaults: true,
+				// the authentication is checked by the middlewares of the secured routes
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+
+		if v.config.ValidateRequests {
+			err = openapi3filter.ValidateRequest(c, requestInput)
+			if err != nil {
+				utils.GetLoggerFromCtx(c).WithError(err).Debug("the request does not match the openapi document")
+				httputils.JSONError(c, newOpenAPIRequestAPIError(err))
+				c.Abort()
+				return
+			}
+		}
+
+		if !v.config.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		w := &openAPIResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		// the errors must be sent before checking the response
+		renderErrors(c)
+		c.Writer = w.ResponseWriter
+
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: requestInput,
+			Status:                 w.Status(),
+			Header:                 w.Header(),
+			Options: &openapi3filter.Options{
+				MultiError:            true,
+				IncludeResponseStatus: true,
+			},
+		}
+		responseInput.SetBodyBytes(w.body.Bytes())
+		err = openapi3filter.ValidateResponse(c, responseInput)
+		if err != nil {
+			utils.GetLoggerFromCtx(c).WithError(err).
+				Errorf("the response of %s %s does not match the openapi document", c.Request.Method, c.FullPath())
+			// the headers describing the invalid response must not be sent with the error
+			c.Writer.Header().Del(httputils.HeaderNameETag)
+			c.Writer.Header().Del(httputils.HeaderNameLastModified)
+			c.Writer.Header().Del(httputils.HeaderNameLocation)
+			apiErr := model.ErrResponseValidation
+			apiErr.Details = appendOpenAPIFieldErrors(nil, err, "$")
+			httputils.JSONError(c, apiErr)
+			return
+		}
+
+		c.Writer.WriteHeaderNow()
+		_, _ = c.Writer.Write(w.body.Bytes())
+	}
+}
+
+// newOpenAPIRequestAPIError converts the errors of the request validation to an APIError
+func newOpenAPIRequestAPIError(err error) model.APIError {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return model.ErrRequestTooLarge
+	}
+
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) && requestErr.RequestBody != nil {
+		var parseErr *openapi3filter.ParseError
+		if errors.As(requestErr.Err, &parseErr) {
+			return model.ErrBadRequestFormat
+		}
+		if requestErr.Err == nil && strings.HasPrefix(requestErr.Reason, "header Content-Type has unexpected value") {
+			return model.ErrUnsupportedMediaType
+		}
+	}
+
+	apiErr := model.ErrDataValidation
+	apiErr.Details = appendOpenAPIFieldErrors(nil, err, "$")
+	return apiErr
+}
+
+// appendOpenAPIFieldErrors appends a FieldError for each error found by the validation of the field
+func appendOpenAPIFieldErrors(details []model.FieldError, err error, field string) []model.FieldError {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, err := range e {
+			details = appendOpenAPIFieldErrors(details, err, field)
+		}
+		return details
+	case *openapi3filter.RequestError:
+		if e.Parameter != nil {
+			field = e.Parameter.In + "." + e.Parameter.Name
+		}
+		if errors.Is(e.Err, openapi3filter.ErrInvalidRequired) {
+			return append(details, newOpenAPIFieldError(field, "required", "", e.Reason))
+		}
+		if e.Err != nil {
+			return appendOpenAPIFieldErrors(details, e.Err, field)
+		}
+		return append(details, newOpenAPIFieldError(field, "openapi", "", e.Reason))
+	case *openapi3filter.ResponseError:
+		if e.Err != nil {
+			return appendOpenAPIFieldErrors(details, e.Err, field)
+		}
+		return append(details, newOpenAPIFieldError(field, "openapi", "", e.Reason))
+	case *openapi3.SchemaError:
+		for _, key := range e.JSONPointer() {
+			if _, errIndex := strconv.Atoi(key); errIndex == nil {
+				field += "[" + key + "]"
+			} else {
+				field += "." + key
+			}
+		}
+		constraint, param := getOpenAPIConstraint(e)
+		return append(details, newOpenAPIFieldError(field, constraint, param, e.Reason))
+	}
+	return append(details, newOpenAPIFieldError(field, "openapi", "", err.Error()))
+}
+
+func newOpenAPIFieldError(field, constraint, param, reason string) model.FieldError {
+	return model.FieldError{
+		Field:       field,
+		Constraint:  constraint,
+		Description: i18n.Sprintf(i18n.DefaultLanguage(), i18n.KeyPrefixValidation+constraint, reason, param),
+		Param:       param,
+	}
+}
+
+// getOpenAPIConstraint returns the validator tag equivalent to the failed schema keyword, to share their messages
+func getOpenAPIConstraint(e *openapi3.SchemaError) (string, string) {
+	schema := e.Schema
+	if schema == nil {
+		return e.SchemaField, ""
+	}
+	switch e.SchemaField {
+	case "minLength":
+		return "min", strconv.FormatUint(schema.MinLength, 10)
+	case "minItems":
+		return "min", strconv.FormatUint(schema.MinItems, 10)
+	case "minimum":
+		if schema.Min != nil {
+			return "min", strconv.FormatFloat(*schema.Min, 'f', -1, 64)
+		}
+	case "maxLength":
+		if schema.MaxLength != nil {
+			return "max", strconv.FormatUint(*schema.MaxLength, 10)
+		}
+	case "maxItems":
+		if schema.MaxItems != nil {
+			return "max", strconv.FormatUint(*schema.MaxItems, 10)
+		}
+	case "maximum":
+		if schema.Max != nil {
+			return "max", strconv.FormatFloat(*schema.Max, 'f', -1, 64)
+		}
+	case "enum":
+		values := make([]string, 0, len(schema.Enum))
+		for _, v := range schema.Enum {
+			values = append(values, fmt.Sprint(v))
+		}
+		return "oneof", strings.Join(values, " ")
+	case "pattern":
+		return "regexp", schema.Pattern
+	case "type":
+		if schema.Type != nil {
+			return "type", strings.Join(schema.Type.Slice(), " ")
+		}
+	case "format":
+		return "format", schema.Format
+	}
+	return e.SchemaField, ""
+}
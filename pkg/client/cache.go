@@ -0,0 +1,175 @@
+package client
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	headerNameContentLength = "Content-Length"
+	headerNameIfNoneMatch   = "If-None-Match"
+
+	// DefaultCacheSize is the number of responses kept by the caches created with a size of zero or less
+	DefaultCacheSize = 1000
+)
+
+// Cache keeps the responses of the GET requests with their ETag. The next requests of the same URLs are sent with
+// the If-None-Match header, and the cached body is used when the api answers 304 Not Modified.
+// The least recently used responses are evicted once the cache is full. A Cache is safe for concurrent use,
+// and can be shared by several clients of the same api.
+type Cache struct {
+	maxEntries int
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+
+	hits   uint64
+	misses uint64
+}
+
+// CacheStats are the statistics of a Cache
+type CacheStats struct {
+	// Hits is the number of responses served from the cache, after a 304 Not Modified of the api
+	Hits uint64
+	// Misses is the number of GET requests whose body was sent by the api
+	Misses uint64
+	// Entries is the number of responses in the cache
+	Entries int
+}
+
+type cacheEntry struct {
+	url      string
+	etag     string
+	response *response
+}
+
+// NewCache returns a cache keeping up to maxEntries responses, DefaultCacheSize when maxEntries is zero or less
+func NewCache(maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheSize
+	}
+	return &Cache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// WithCache enables the conditional GET requests, using the given cache
+func WithCache(cache *Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// Stats returns the current statistics of the cache
+func (c *Cache) Stats() CacheStats {
+	c.mutex.Lock()
+	entries := c.lru.Len()
+	c.mutex.Unlock()
+	return CacheStats{
+		Hits:    atomic.LoadUint64(&c.hits),
+		Misses:  atomic.LoadUint64(&c.misses),
+		Entries: entries,
+	}
+}
+
+// Purge removes all the responses of the cache, keeping its statistics
+func (c *Cache) Purge() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+func (c *Cache) get(url string) *cacheEntry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	elem, ok := c.entries[url]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry)
+}
+
+func (c *Cache) set(url, etag string, resp *response) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry := &cacheEntry{url: url, etag: etag, response: resp}
+	if elem, ok := c.entries[url]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[url] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).url)
+	}
+}
+
+func (c *Cache) remove(url string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if elem, ok := c.entries[url]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, url)
+	}
+}
+
+// beforeSend adds the If-None-Match header to the GET requests of a cached URL, and returns the cached entry
+func (c *Cache) beforeSend(req *http.Request) *cacheEntry {
+	if req.Method != http.MethodGet {
+		return nil
+	}
+	entry := c.get(req.URL.String())
+	if entry != nil {
+		req.Header.Set(headerNameIfNoneMatch, entry.etag)
+	}
+	return entry
+}
+
+// afterSend returns the response to use for the request: the cached one on 304 Not Modified, resp otherwise.
+// The successful responses having an ETag are stored, and those of the other methods invalidate the URL
+// as the resource has been modified (RFC 7234 section 4.4).
+func (c *Cache) afterSend(req *http.Request, entry *cacheEntry, resp *response) *response {
+	url := req.URL.String()
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		if resp.statusCode < http.StatusBadRequest {
+			c.remove(url)
+		}
+		return resp
+	}
+	if req.Method != http.MethodGet {
+		return resp
+	}
+
+	if resp.statusCode == http.StatusNotModified && entry != nil {
+		atomic.AddUint64(&c.hits, 1)
+		cached := *entry.response
+		// the 304 response gives the up to date headers of the cached response
+		cached.header = entry.response.header.Clone()
+		for k, values := range resp.header {
+			if k != headerNameContentLength {
+				cached.header[k] = values
+			}
+		}
+		return &cached
+	}
+
+	atomic.AddUint64(&c.misses, 1)
+	etag := resp.header.Get(headerNameETag)
+	switch {
+	case resp.statusCode == http.StatusOK && etag != "":
+		c.set(url, etag, resp)
+	case resp.statusCode < http.StatusInternalServerError:
+		// the cached response is no longer the current one, e.g. the resource is deleted
+		c.remove(url)
+	}
+	return resp
+}
@@ -0,0 +1,285 @@
+// Package client is the Go client of the api, with its models copied from storage/model.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	headerNameAccept         = "Accept"
+	headerNameAuthorization  = "Authorization"
+	headerNameContentType    = "Content-Type"
+	headerNameCorrelationID  = "correlationID"
+	headerNameETag           = "ETag"
+	headerNameIdempotencyKey = "Idempotency-Key"
+	headerNameIfMatch        = "If-Match"
+	headerNameRetryAfter     = "Retry-After"
+
+	headerValueApplicationJSON        = "application/json"
+	headerValueApplicationProblemJSON = "application/problem+json"
+)
+
+// RetryPolicy describes how the idempotent requests are retried when the api or the network fails.
+// The delay between two attempts doubles from InitialBackoff up to MaxBackoff, with a random jitter,
+// unless the api gives a Retry-After header.
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of the clients created without WithRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+}
+
+// Client calls the api. It is safe for concurrent use.
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+	cache       *Cache
+	// etags are the ETags of the resources last read or written, sent in the If-Match header of their next writes
+	etags etagStore
+	// authorization is the value of the Authorization header of the requests
+	authorization string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used to send the requests, http.DefaultClient by default
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetryPolicy sets the retry policy of the idempotent requests. A zero policy disables the retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithBearerToken authenticates the requests with the given bearer token
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.authorization = "Bearer " + token
+	}
+}
+
+// WithBasicAuth authenticates the requests with the given username and password
+func WithBasicAuth(username, password string) Option {
+	return func(c *Client) {
+		c.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	}
+}
+
+// New returns a client of the api available at baseURL, e.g. https://api.example.com
+func New(baseURL string, options ...Option) *Client {
+	c := &Client{
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		httpClient:  http.DefaultClient,
+		retryPolicy: DefaultRetryPolicy,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a context whose requests are sent with the correlationID header, to follow them in the
+// logs of the api. Without it, the api generates a correlation ID for each request, given in the errors.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// CorrelationIDFromContext returns the correlation ID set by WithCorrelationID
+func CorrelationIDFromContext(ctx context.Context) (string, bool) {
+	correlationID, ok := ctx.Value(correlationIDKey{}).(string)
+	return correlationID, ok && correlationID != ""
+}
+
+// request describes a call to the api
+type request struct {
+	method string
+	path   string
+	body   interface{}
+	header http.Header
+	// idempotent requests are retried, the POST requests are when they are given an idempotency key
+	idempotent bool
+}
+
+// response is a successful response of the api, its body already read
+type response struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// do sends the request, retrying it according to the retry policy when it is idempotent,
+// and returns the response when its status is a success, an *Error otherwise
+func (c *Client) do(ctx context.Context, r request) (*response, error) {
+	var body []byte
+	if r.body != nil {
+		var err error
+		body, err = json.Marshal(r.body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode the request body: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, r, body)
+		if err == nil && resp.statusCode < http.StatusBadRequest {
+			return resp, nil
+		}
+		if err == nil {
+			apiErr := newError(resp)
+			if apiErr.CorrelationID == "" {
+				// the api only answers the correlation ID it generated
+				apiErr.CorrelationID, _ = CorrelationIDFromContext(ctx)
+			}
+			err = apiErr
+		}
+
+		if !r.idempotent || attempt >= c.retryPolicy.MaxRetries || !isRetryable(err) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.backoff(attempt, resp)):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, r request, body []byte) (*response, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	requestURL, err := c.resolveURL(r.path)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, requestURL, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	for k, values := range r.header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set(headerNameAccept, headerValueApplicationJSON)
+	if c.authorization != "" {
+		req.Header.Set(headerNameAuthorization, c.authorization)
+	}
+	if body != nil {
+		req.Header.Set(headerNameContentType, headerValueApplicationJSON)
+	}
+	if correlationID, ok := CorrelationIDFromContext(ctx); ok {
+		req.Header.Set(headerNameCorrelationID, correlationID)
+	}
+	var cached *cacheEntry
+	if c.cache != nil {
+		cached = c.cache.beforeSend(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	result := &response{
+		statusCode: resp.StatusCode,
+		header:     resp.Header,
+		body:       respBody,
+	}
+	if c.cache != nil {
+		result = c.cache.afterSend(req, cached, result)
+	}
+	return result, nil
+}
+
+// resolveURL returns the URL of the request of path. The paths given as complete URLs, e.g. by the next links,
+// must be on the api: the credentials of the client are not sent to another origin.
+func (c *Client) resolveURL(path string) (string, error) {
+	if !isAbsoluteURL(path) {
+		return c.baseURL + path, nil
+	}
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(u.Scheme, base.Scheme) || !strings.EqualFold(u.Host, base.Host) {
+		return "", fmt.Errorf("the url %s is not on the api %s", path, c.baseURL)
+	}
+	return path, nil
+}
+
+// backoff returns the delay before the next attempt, given by the Retry-After header of the response when any
+func (c *Client) backoff(attempt int, resp *response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.header.Get(headerNameRetryAfter)); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	delay := c.retryPolicy.InitialBackoff << uint(attempt)
+	if delay > c.retryPolicy.MaxBackoff || delay <= 0 {
+		delay = c.retryPolicy.MaxBackoff
+	}
+	// the jitter spreads the retries of the clients failing at the same time
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// isRetryable returns true for the network errors and the errors telling the api is temporarily unable to answer
+func isRetryable(err error) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		// retrying would fail the same way once the context is done
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return apiErr.Type == ErrIdempotencyRequestInProgress.Type || apiErr.Type == ErrConflict.Type
+}
+
+func decodeJSON(resp *response, v interface{}) error {
+	err := json.Unmarshal(resp.body, v)
+	if err != nil {
+		return fmt.Errorf("unable to decode the response body: %w", err)
+	}
+	return nil
+}
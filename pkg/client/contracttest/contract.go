@@ -0,0 +1,114 @@
+// Package contracttest records the interactions of a consumer with the api as contract files, and verifies them
+// against the api to detect the changes breaking the consumers.
+//
+// On the consumer side, the interactions are recorded by sending the requests through a Recorder, e.g. while
+// running the tests of the consumer against an api started with the db in memory mode:
+//
+//	recorder := contracttest.NewRecorder("my-service", nil)
+//	c := client.New(apiURL, client.WithHTTPClient(&http.Client{Transport: recorder}))
+//	// ... the calls the consumer depends on
+//	err := recorder.Contract().WriteFile("contracts/my-service.json")
+//
+// On the provider side, the contract files are given to the api repository, whose contracts verify command replays
+// them against its router with the db in memory, using Verify.
+//
+// The requests of a contract can use values of the responses of its previous interactions with placeholders,
+// like {{0.body.id}} or {{0.header.ETag}}, where 0 is the index or the name of the interaction and the body value
+// is given by its path (items.0.id). The recorder adds them for the identifiers and ETags reused by the consumer.
+// The responses are checked by their shape: their status, their headers, and that their body has the same
+// structure as the recorded one, with the values of the same JSON types. The new fields are ignored.
+package contracttest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Contract is the list of the interactions a consumer depends on, replayed in order
+type Contract struct {
+	Consumer     string         `json:"consumer"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a request sent by the consumer with the response it expects
+type Interaction struct {
+	// Name identifies the interaction in the placeholders of the next ones, in addition to its index
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Request     Request  `json:"request"`
+	Response    Response `json:"response"`
+}
+
+type Request struct {
+	Method string            `json:"method"`
+	Path   string            `json:"path"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   json.RawMessage   `json:"body,omitempty"`
+}
+
+// Response is the expected response. The Content-Type header is compared without its parameters, and the body
+// by its shape.
+type Response struct {
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   json.RawMessage   `json:"body,omitempty"`
+}
+
+// Load reads a contract file
+func Load(file string) (*Contract, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	contract := &Contract{}
+	err = json.Unmarshal(data, contract)
+	if err != nil {
+		return nil, err
+	}
+	return contract, nil
+}
+
+// LoadDir reads the contract files of a directory, the .json files, sorted by name
+func LoadDir(dir string) ([]*Contract, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	contracts := make([]*Contract, 0, len(files))
+	for _, file := range files {
+		contract, err := Load(file)
+		if err != nil {
+			return nil, &FileError{File: file, Err: err}
+		}
+		contracts = append(contracts, contract)
+	}
+	return contracts, nil
+}
+
+// WriteFile writes the contract as an indented JSON file
+func (c *Contract) WriteFile(file string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, append(data, '\n'), os.FileMode(0644))
+}
+
+// FileError is an error while reading a contract file
+type FileError struct {
+	File string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return "contract " + e.File + ": " + e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
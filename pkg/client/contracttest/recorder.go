@@ -0,0 +1,245 @@
+package contracttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	headerNameContentType = "Content-Type"
+	headerNameETag        = "ETag"
+
+	// identifierKey is the name of the fields whose values are replaced by placeholders when they are reused
+	identifierKey = "id"
+)
+
+// ignoredRequestHeaders are the request headers not recorded, as the api does not depend on them
+// or they are specific to the consumer environment
+var ignoredRequestHeaders = map[string]bool{
+	"Accept-Encoding": true,
+	"Authorization":   true,
+	"Content-Length":  true,
+	"Cookie":          true,
+	"Correlationid":   true,
+	"User-Agent":      true,
+}
+
+// Recorder is an http.RoundTripper recording the interactions sent through it in a contract.
+// It is safe for concurrent use, the interactions are recorded in the order of their responses.
+type Recorder struct {
+	transport http.RoundTripper
+
+	mutex    sync.Mutex
+	contract Contract
+	// values are the identifiers and ETags of the recorded responses, with their placeholders
+	values []recordedValue
+}
+
+type recordedValue struct {
+	value       string
+	placeholder string
+}
+
+// NewRecorder returns a recorder of the interactions of consumer, sending the requests with transport,
+// http.DefaultTransport when nil
+func NewRecorder(consumer string, transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{
+		transport: transport,
+		contract: Contract{
+			Consumer:     consumer,
+			Interactions: []*Interaction{},
+		},
+	}
+}
+
+// RoundTrip sends the request and records it with its response
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	r.record(req, reqBody, resp, respBody)
+	return resp, nil
+}
+
+// Contract returns a copy of the recorded contract
+func (r *Recorder) Contract() *Contract {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	contract := r.contract
+	contract.Interactions = append([]*Interaction{}, r.contract.Interactions...)
+	return &contract
+}
+
+func (r *Recorder) record(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	interaction := &Interaction{
+		Request: Request{
+			Method: req.Method,
+			Path:   r.replaceValues(req.URL.RequestURI()),
+		},
+		Response: Response{
+			Status: resp.StatusCode,
+		},
+	}
+	interaction.Description = interaction.Request.Method + " " + interaction.Request.Path
+
+	for name := range req.Header {
+		if !ignoredRequestHeaders[name] {
+			if interaction.Request.Header == nil {
+				interaction.Request.Header = map[string]string{}
+			}
+			interaction.Request.Header[name] = r.replaceValues(req.Header.Get(name))
+		}
+	}
+	if isJSON(req.Header.Get(headerNameContentType), reqBody) {
+		interaction.Request.Body = r.replaceBodyValues(reqBody)
+	}
+
+	if contentType := resp.Header.Get(headerNameContentType); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err == nil {
+			interaction.Response.Header = map[string]string{headerNameContentType: mediaType}
+		}
+	}
+	if isJSON(resp.Header.Get(headerNameContentType), respBody) {
+		interaction.Response.Body = respBody
+	}
+
+	index := len(r.contract.Interactions)
+	r.contract.Interactions = append(r.contract.Interactions, interaction)
+	r.addValues(index, resp, respBody)
+}
+
+// addValues keeps the ETag and the identifiers of the response of the interaction index,
+// to replace them by placeholders in the next requests
+func (r *Recorder) addValues(index int, resp *http.Response, body []byte) {
+	prefix := "{{" + strconv.Itoa(index) + "."
+	if etag := resp.Header.Get(headerNameETag); etag != "" {
+		r.values = append(r.values, recordedValue{value: etag, placeholder: prefix + "header." + headerNameETag + "}}"})
+	}
+	if interaction := r.contract.Interactions[index]; interaction.Response.Body != nil {
+		var data interface{}
+		if json.Unmarshal(body, &data) == nil {
+			walkIdentifiers(data, "", func(path, value string) {
+				r.values = append(r.values, recordedValue{value: value, placeholder: prefix + "body." + path + "}}"})
+			})
+		}
+	}
+	// the longest values are replaced first, not to replace a part of them
+	sort.SliceStable(r.values, func(i, j int) bool {
+		return len(r.values[i].value) > len(r.values[j].value)
+	})
+}
+
+// walkIdentifiers calls f for each identifier of data, with its path
+func walkIdentifiers(data interface{}, path string, f func(path, value string)) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch d := data.(type) {
+	case map[string]interface{}:
+		for k, v := range d {
+			if s, ok := v.(string); ok && k == identifierKey && s != "" {
+				f(join(k), s)
+				continue
+			}
+			walkIdentifiers(v, join(k), f)
+		}
+	case []interface{}:
+		for i, v := range d {
+			walkIdentifiers(v, join(strconv.Itoa(i)), f)
+		}
+	}
+}
+
+func (r *Recorder) replaceValues(s string) string {
+	for _, v := range r.values {
+		s = strings.Replace(s, v.value, v.placeholder, -1)
+	}
+	return s
+}
+
+// replaceBodyValues replaces the string values of body equal to a recorded value
+func (r *Recorder) replaceBodyValues(body []byte) json.RawMessage {
+	var data interface{}
+	if len(r.values) == 0 || json.Unmarshal(body, &data) != nil {
+		return body
+	}
+	data = r.replaceDataValues(data)
+	result, err := json.Marshal(data)
+	if err != nil {
+		return body
+	}
+	return result
+}
+
+func (r *Recorder) replaceDataValues(data interface{}) interface{} {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		for k, v := range d {
+			d[k] = r.replaceDataValues(v)
+		}
+	case []interface{}:
+		for i, v := range d {
+			d[i] = r.replaceDataValues(v)
+		}
+	case string:
+		for _, v := range r.values {
+			if d == v.value {
+				return v.placeholder
+			}
+		}
+	}
+	return data
+}
+
+// isJSON returns true when the body is a JSON document, sent with a JSON media type like application/problem+json
+func isJSON(contentType string, body []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || len(body) == 0 {
+		return false
+	}
+	return (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) && json.Valid(body)
+}
+
+func (i *Interaction) String() string {
+	if i.Name != "" {
+		return fmt.Sprintf("%s (%s)", i.Name, i.Description)
+	}
+	return i.Description
+}
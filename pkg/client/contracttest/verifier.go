@@ -0,0 +1,243 @@
+package contracttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var regexpPlaceholder = regexp.MustCompile(`\{\{\s*([^.{}\s]+)\.(header|body)(?:\.([^{}\s]*))?\s*\}\}`)
+
+// VerificationError lists the mismatches between a contract and the responses of the api
+type VerificationError struct {
+	Consumer   string
+	Mismatches []string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("contract of %s broken:\n\t%s", e.Consumer, strings.Join(e.Mismatches, "\n\t"))
+}
+
+// Verify replays the interactions of the contract in order against handler, and returns a *VerificationError
+// when the responses do not match the expected ones. As the interactions depend on the previous ones,
+// handler must be a new instance of the api, with an empty db.
+func Verify(handler http.Handler, contract *Contract) error {
+	v := &verifier{
+		responses: map[string]*recordedResponse{},
+	}
+	for i, interaction := range contract.Interactions {
+		mismatches := v.verify(handler, interaction)
+		for _, m := range mismatches {
+			v.mismatches = append(v.mismatches, fmt.Sprintf("interaction %d %s: %s", i, interaction, m))
+		}
+		v.responses[strconv.Itoa(i)] = v.last
+		if interaction.Name != "" {
+			v.responses[interaction.Name] = v.last
+		}
+	}
+	if len(v.mismatches) > 0 {
+		return &VerificationError{Consumer: contract.Consumer, Mismatches: v.mismatches}
+	}
+	return nil
+}
+
+type verifier struct {
+	// responses are the responses of the replayed interactions, by index and name
+	responses  map[string]*recordedResponse
+	last       *recordedResponse
+	mismatches []string
+}
+
+type recordedResponse struct {
+	header http.Header
+	body   interface{}
+}
+
+func (v *verifier) verify(handler http.Handler, interaction *Interaction) []string {
+	v.last = nil
+	var errs []string
+	resolve := func(s string, escape func(string) string) string {
+		result, err := v.resolve(s, escape)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		return result
+	}
+
+	body := resolve(string(interaction.Request.Body), escapeJSONString)
+	req := httptest.NewRequest(interaction.Request.Method, resolve(interaction.Request.Path, nil), strings.NewReader(body))
+	for name, value := range interaction.Request.Header {
+		req.Header.Set(name, resolve(value, nil))
+	}
+	expectedHeader := map[string]string{}
+	for name, value := range interaction.Response.Header {
+		expectedHeader[name] = resolve(value, nil)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	resp := w.Result()
+
+	v.last = &recordedResponse{header: resp.Header}
+	if w.Body.Len() > 0 {
+		var data interface{}
+		if json.Unmarshal(w.Body.Bytes(), &data) == nil {
+			v.last.body = data
+		}
+	}
+
+	if resp.StatusCode != interaction.Response.Status {
+		errs = append(errs, fmt.Sprintf("status %d expected, got %d: %s", interaction.Response.Status,
+			resp.StatusCode, bytes.TrimSpace(w.Body.Bytes())))
+		// the next checks would only list the differences between the success and the error responses
+		return errs
+	}
+	for name, expected := range expectedHeader {
+		actual := resp.Header.Get(name)
+		if strings.EqualFold(name, headerNameContentType) {
+			actual, _, _ = mime.ParseMediaType(actual)
+		}
+		if actual != expected {
+			errs = append(errs, fmt.Sprintf("header %s %q expected, got %q", name, expected, actual))
+		}
+	}
+	if interaction.Response.Body != nil {
+		var expected interface{}
+		if err := json.Unmarshal(interaction.Response.Body, &expected); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid expected body: %v", err))
+		} else if v.last.body == nil {
+			errs = append(errs, fmt.Sprintf("JSON body expected, got %q", w.Body.String()))
+		} else {
+			errs = append(errs, matchShape("$", expected, v.last.body)...)
+		}
+	}
+	return errs
+}
+
+// resolve replaces the placeholders of s by the values of the previous responses, escaped with escape if not nil
+func (v *verifier) resolve(s string, escape func(string) string) (string, error) {
+	var err error
+	result := regexpPlaceholder.ReplaceAllStringFunc(s, func(placeholder string) string {
+		m := regexpPlaceholder.FindStringSubmatch(placeholder)
+		value, e := v.value(m[1], m[2], m[3])
+		if e != nil {
+			if err == nil {
+				err = fmt.Errorf("placeholder %s: %v", placeholder, e)
+			}
+			return placeholder
+		}
+		if escape != nil {
+			return escape(value)
+		}
+		return value
+	})
+	return result, err
+}
+
+func (v *verifier) value(ref, source, path string) (string, error) {
+	resp := v.responses[ref]
+	if resp == nil {
+		return "", fmt.Errorf("no previous interaction %s", ref)
+	}
+	if source == "header" {
+		value := resp.header.Get(path)
+		if value == "" {
+			return "", fmt.Errorf("no header %s in the response", path)
+		}
+		return value, nil
+	}
+
+	data := resp.body
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			switch d := data.(type) {
+			case map[string]interface{}:
+				data = d[key]
+			case []interface{}:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= len(d) {
+					return "", fmt.Errorf("no value %s in the response body", path)
+				}
+				data = d[i]
+			default:
+				data = nil
+			}
+		}
+	}
+	switch d := data.(type) {
+	case string:
+		return d, nil
+	case float64, bool:
+		return fmt.Sprint(d), nil
+	}
+	return "", fmt.Errorf("no value %s in the response body", path)
+}
+
+// matchShape returns the differences between the structure of expected and actual. The fields of expected must
+// be in actual with a value of the same JSON type, the elements of the actual arrays must match the first element
+// of the expected ones. The null values of expected match any value.
+func matchShape(path string, expected, actual interface{}) []string {
+	if expected == nil {
+		return nil
+	}
+	if jsonType(expected) != jsonType(actual) {
+		return []string{fmt.Sprintf("%s: %s expected, got %s", path, jsonType(expected), jsonType(actual))}
+	}
+
+	var errs []string
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a := actual.(map[string]interface{})
+		for k, ev := range e {
+			av, ok := a[k]
+			if !ok {
+				if ev != nil {
+					errs = append(errs, fmt.Sprintf("%s.%s: missing", path, k))
+				}
+				continue
+			}
+			errs = append(errs, matchShape(path+"."+k, ev, av)...)
+		}
+	case []interface{}:
+		if len(e) == 0 {
+			return nil
+		}
+		for i, av := range actual.([]interface{}) {
+			errs = append(errs, matchShape(fmt.Sprintf("%s[%d]", path, i), e[0], av)...)
+		}
+	}
+	return errs
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// escapeJSONString escapes s to be inserted in a JSON string
+func escapeJSONString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
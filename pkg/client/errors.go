@@ -0,0 +1,99 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/denouche/go-api-skeleton/pkg/client/model"
+)
+
+// Error is an error answered by the api. It can be compared with the Err* values using errors.Is,
+// which match on the error type: errors.Is(err, client.ErrNotFound)
+type Error struct {
+	StatusCode  int
+	Type        string
+	Description string
+	Details     []model.FieldError
+	// CorrelationID identifies the request in the logs of the api
+	CorrelationID string
+}
+
+var (
+	ErrBadFormat                    = &Error{Type: "bad_format"}
+	ErrDataValidation               = &Error{Type: "data_validation"}
+	ErrAdmissionDenied              = &Error{Type: "admission_denied"}
+	ErrNotFound                     = &Error{Type: "not_found"}
+	ErrMethodNotAllowed             = &Error{Type: "method_not_allowed"}
+	ErrAlreadyExists                = &Error{Type: "already_exists"}
+	ErrPreconditionFailed           = &Error{Type: "precondition_failed"}
+	ErrIdempotencyRequestInProgress = &Error{Type: "idempotency_request_in_progress"}
+	ErrPatchFailed                  = &Error{Type: "patch_failed"}
+	ErrUnsupportedMediaType         = &Error{Type: "unsupported_media_type"}
+	ErrConflict                     = &Error{Type: "conflict"}
+	ErrReferenceNotFound            = &Error{Type: "reference_not_found"}
+	ErrIdempotencyKeyReused         = &Error{Type: "idempotency_key_reused"}
+	ErrPreconditionRequired         = &Error{Type: "precondition_required"}
+	ErrRequestTooLarge              = &Error{Type: "request_too_large"}
+	ErrBatchAborted                 = &Error{Type: "batch_aborted"}
+	ErrInternalServer               = &Error{Type: "internal_server_error"}
+	ErrAdmissionWebhookFailed       = &Error{Type: "admission_webhook_failed"}
+	ErrServiceUnavailable           = &Error{Type: "service_unavailable"}
+	ErrTimeout                      = &Error{Type: "timeout"}
+)
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("api error %d %s", e.StatusCode, e.Type)
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+	for _, d := range e.Details {
+		msg += fmt.Sprintf(", %s: %s", d.Field, d.Description)
+	}
+	return msg
+}
+
+// Is reports whether target is an *Error of the same type
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Type == e.Type
+}
+
+// newError decodes the error of a response, sent in the legacy format or as an RFC 7807 problem details
+func newError(resp *response) *Error {
+	e := &Error{
+		StatusCode:    resp.statusCode,
+		CorrelationID: resp.header.Get(headerNameCorrelationID),
+	}
+
+	if strings.HasPrefix(resp.header.Get(headerNameContentType), headerValueApplicationProblemJSON) {
+		problem := model.ProblemDetails{}
+		if json.Unmarshal(resp.body, &problem) == nil {
+			// the problem type is the error type appended to a base URI
+			e.Type = problem.Type[strings.LastIndexAny(problem.Type, ":/")+1:]
+			e.Description = problem.Detail
+			for _, pe := range problem.Errors {
+				e.Details = append(e.Details, model.FieldError{
+					Field:       pe.Pointer,
+					Constraint:  pe.Constraint,
+					Description: pe.Detail,
+				})
+			}
+		}
+	} else {
+		apiErr := model.APIError{}
+		if json.Unmarshal(resp.body, &apiErr) == nil {
+			e.Type = apiErr.Type
+			e.Description = apiErr.Description
+			e.Details = apiErr.Details
+		}
+	}
+
+	if e.Type == "" {
+		// not an error of the api, e.g. sent by a proxy
+		e.Type = strings.ToLower(strings.Replace(http.StatusText(resp.statusCode), " ", "_", -1))
+		e.Description = strings.TrimSpace(string(resp.body))
+	}
+	return e
+}
@@ -0,0 +1,31 @@
+package client
+
+import "sync"
+
+// etagStore remembers the ETag of the last version of each resource read or written by the client, to condition
+// its next update or deletion. It is safe for concurrent use.
+type etagStore struct {
+	mu    sync.Mutex
+	etags map[string]string
+}
+
+// get returns the ETag remembered for the resource at path, empty if it is unknown
+func (s *etagStore) get(path string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.etags[path]
+}
+
+// set remembers the ETag of the resource at path, or forgets it when etag is empty
+func (s *etagStore) set(path, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if etag == "" {
+		delete(s.etags, path)
+		return
+	}
+	if s.etags == nil {
+		s.etags = make(map[string]string)
+	}
+	s.etags[path] = etag
+}
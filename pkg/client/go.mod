@@ -1,3 +1,3 @@
 module github.com/denouche/go-api-skeleton/pkg/client
 
-go 1.12
+go 1.13
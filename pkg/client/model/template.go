@@ -16,5 +16,32 @@ type Template struct {
 // @openapi:schema
 type TemplateEditable struct {
 	// Add here your model properties, and don't forget to modify SQL request in corresponding DAO file if any
-	Name string `json:"name" bson:"name" validate:"required"`
+	Name string `json:"name" bson:"name" validate:"required,unique=name"`
+}
+
+// LastModified returns the date of the last modification of the template, used in the Last-Modified header
+func (t *Template) LastModified() time.Time {
+	if t.UpdatedAt != nil {
+		return *t.UpdatedAt
+	}
+	return t.CreatedAt
+}
+
+// @openapi:schema
+type TemplateBatchCreateRequest struct {
+	Mode  string             `json:"mode" validate:"omitempty,oneof=all_or_nothing best_effort"`
+	Items []TemplateEditable `json:"items" validate:"required,min=1"`
+}
+
+// @openapi:schema
+type TemplateBatchUpdateItem struct {
+	ID      string           `json:"id" validate:"required"`
+	IfMatch string           `json:"ifMatch"`
+	Data    TemplateEditable `json:"data"`
+}
+
+// @openapi:schema
+type TemplateBatchUpdateRequest struct {
+	Mode  string                    `json:"mode" validate:"omitempty,oneof=all_or_nothing best_effort"`
+	Items []TemplateBatchUpdateItem `json:"items" validate:"required,min=1"`
 }
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const headerNameLink = "Link"
+
+var regexpLinkNext = regexp.MustCompile(`<([^>]*)>\s*;[^,]*\brel="?next"?`)
+
+// pageIterator fetches the pages of a list one by one, following the next links given in the Link header (RFC 8288).
+// The lists answered without a next link have a single page.
+type pageIterator struct {
+	ctx     context.Context
+	client  *Client
+	nextURL string
+	err     error
+}
+
+func newPageIterator(ctx context.Context, client *Client, path string) *pageIterator {
+	return &pageIterator{
+		ctx:     ctx,
+		client:  client,
+		nextURL: client.baseURL + path,
+	}
+}
+
+// next returns the response of the next page, or false when there is no more page or on error
+func (it *pageIterator) next() (*response, bool) {
+	if it.err != nil || it.nextURL == "" {
+		return nil, false
+	}
+
+	current := it.nextURL
+	resp, err := it.client.do(it.ctx, request{
+		method:     http.MethodGet,
+		path:       current,
+		idempotent: true,
+	})
+	if err != nil {
+		it.err = err
+		return nil, false
+	}
+
+	it.nextURL = ""
+	for _, link := range resp.header[http.CanonicalHeaderKey(headerNameLink)] {
+		if m := regexpLinkNext.FindStringSubmatch(link); m != nil {
+			// the link can be relative to the current page
+			base, err := url.Parse(current)
+			if err == nil {
+				if next, err := base.Parse(m[1]); err == nil {
+					it.nextURL = next.String()
+				}
+			}
+			break
+		}
+	}
+	if it.nextURL != "" {
+		// a next link to another origin fails the iteration, instead of sending it the credentials of the client
+		if _, err := it.client.resolveURL(it.nextURL); err != nil {
+			it.err = fmt.Errorf("unable to follow the next page link: %w", err)
+			it.nextURL = ""
+		}
+	}
+	return resp, true
+}
+
+// isAbsoluteURL returns true for the paths given as complete URLs, e.g. by the next links
+func isAbsoluteURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
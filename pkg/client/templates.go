@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+
+	"github.com/denouche/go-api-skeleton/pkg/client/model"
+)
+
+// ListTemplates returns all the templates, following the pages of the list if any
+func (c *Client) ListTemplates(ctx context.Context) ([]*model.Template, error) {
+	var templates []*model.Template
+	it := c.Templates(ctx)
+	for it.Next() {
+		templates = append(templates, it.Template())
+	}
+	return templates, it.Err()
+}
+
+// Templates returns an iterator over the templates, fetching their pages on demand
+func (c *Client) Templates(ctx context.Context) *TemplateIterator {
+	return &TemplateIterator{
+		pages: newPageIterator(ctx, c, "/templates"),
+	}
+}
+
+// TemplateIterator iterates over the templates:
+//
+//	it := c.Templates(ctx)
+//	for it.Next() {
+//		template := it.Template()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type TemplateIterator struct {
+	pages   *pageIterator
+	page    []*model.Template
+	current *model.Template
+}
+
+// Next advances to the next template, fetching the next page when needed. It returns false at the end or on error.
+func (it *TemplateIterator) Next() bool {
+	for len(it.page) == 0 {
+		resp, ok := it.pages.next()
+		if !ok {
+			it.current = nil
+			return false
+		}
+		it.page = nil
+		if err := decodeJSON(resp, &it.page); err != nil {
+			it.pages.err = err
+			return false
+		}
+	}
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Template returns the current template
+func (it *TemplateIterator) Template() *model.Template {
+	return it.current
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *TemplateIterator) Err() error {
+	return it.pages.err
+}
+
+// GetTemplate returns the template with the given id, or an error matching ErrNotFound
+func (c *Client) GetTemplate(ctx context.Context, id string) (*model.Template, error) {
+	template, _, err := c.GetTemplateWithETag(ctx, id)
+	return template, err
+}
+
+// GetTemplateWithETag returns the template with the given id and the ETag of its version. The client remembers the
+// ETag to condition the next UpdateTemplate or DeleteTemplate of the template.
+func (c *Client) GetTemplateWithETag(ctx context.Context, id string) (*model.Template, string, error) {
+	path := templatePath(id)
+	resp, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       path,
+		idempotent: true,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	template := &model.Template{}
+	err = decodeJSON(resp, template)
+	if err != nil {
+		return nil, "", err
+	}
+	etag := resp.header.Get(headerNameETag)
+	c.etags.set(path, etag)
+	return template, etag, nil
+}
+
+// CreateTemplate creates a template. The request is sent with an idempotency key, so it is safely retried.
+func (c *Client) CreateTemplate(ctx context.Context, data model.TemplateEditable) (*model.Template, error) {
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, request{
+		method:     http.MethodPost,
+		path:       "/templates",
+		body:       data,
+		header:     http.Header{headerNameIdempotencyKey: {key}},
+		idempotent: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	template := &model.Template{}
+	err = decodeJSON(resp, template)
+	if err != nil {
+		return nil, err
+	}
+	c.etags.set(templatePath(template.ID), resp.header.Get(headerNameETag))
+	return template, nil
+}
+
+// UpdateTemplate replaces the data of a template. The update is conditioned by the ETag of the version of the
+// template last read or written by the client: it fails with an error matching ErrPreconditionFailed when the
+// template is modified in the meantime, ErrPreconditionRequired when the client has not read the template yet.
+func (c *Client) UpdateTemplate(ctx context.Context, id string, data model.TemplateEditable) (*model.Template, error) {
+	return c.UpdateTemplateIfMatch(ctx, id, c.etags.get(templatePath(id)), data)
+}
+
+// UpdateTemplateIfMatch is UpdateTemplate conditioned by etag, the ETag of the version of the template the data are
+// based on, e.g. given by GetTemplateWithETag to another client
+func (c *Client) UpdateTemplateIfMatch(ctx context.Context, id, etag string, data model.TemplateEditable) (*model.Template, error) {
+	path := templatePath(id)
+	resp, err := c.do(ctx, request{
+		method:     http.MethodPut,
+		path:       path,
+		body:       data,
+		header:     ifMatchHeader(etag),
+		idempotent: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	template := &model.Template{}
+	err = decodeJSON(resp, template)
+	if err != nil {
+		return nil, err
+	}
+	c.etags.set(path, resp.header.Get(headerNameETag))
+	return template, nil
+}
+
+// DeleteTemplate deletes a template. Like UpdateTemplate, the deletion is conditioned by the ETag of the version of
+// the template last read or written by the client.
+func (c *Client) DeleteTemplate(ctx context.Context, id string) error {
+	return c.DeleteTemplateIfMatch(ctx, id, c.etags.get(templatePath(id)))
+}
+
+// DeleteTemplateIfMatch is DeleteTemplate conditioned by etag, the ETag of the version of the template known by the
+// caller
+func (c *Client) DeleteTemplateIfMatch(ctx context.Context, id, etag string) error {
+	path := templatePath(id)
+	_, err := c.do(ctx, request{
+		method:     http.MethodDelete,
+		path:       path,
+		header:     ifMatchHeader(etag),
+		idempotent: true,
+	})
+	if err != nil {
+		return err
+	}
+	c.etags.set(path, "")
+	return nil
+}
+
+func templatePath(id string) string {
+	return "/templates/" + url.PathEscape(id)
+}
+
+func ifMatchHeader(etag string) http.Header {
+	if etag == "" {
+		return nil
+	}
+	return http.Header{headerNameIfMatch: {etag}}
+}
+
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,238 @@
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/httputils"
+	"github.com/denouche/go-api-skeleton/utils/tracing"
+	jsonpatch "github.com/evanphx/json-patch"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// FailurePolicyFail denies the write when the webhook cannot be called or answers badly
+	FailurePolicyFail = "Fail"
+	// FailurePolicyIgnore admits the write when the webhook cannot be called or answers badly
+	FailurePolicyIgnore = "Ignore"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// Webhook is an HTTP endpoint receiving an AdmissionRequest before the writes of the given entities and operations,
+// all of them when empty, and answering with an AdmissionResponse
+type Webhook struct {
+	Name          string            `mapstructure:"name"`
+	URL           string            `mapstructure:"url"`
+	Entities      []string          `mapstructure:"entities"`
+	Operations    []string          `mapstructure:"operations"`
+	Timeout       time.Duration     `mapstructure:"timeout"`
+	FailurePolicy string            `mapstructure:"failurePolicy"`
+	Headers       map[string]string `mapstructure:"headers"`
+}
+
+func (w *Webhook) matches(entity, operation string) bool {
+	return matchesAny(w.Entities, entity) && matchesAny(w.Operations, operation)
+}
+
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Result is the result of the review of a write by the webhooks
+type Result struct {
+	Allowed bool
+	// Message and Webhook describe the denial
+	Message string
+	Webhook string
+	// Object is the object mutated by the webhooks
+	Object json.RawMessage
+}
+
+// WebhookError is returned when a webhook with the Fail policy cannot be called or answers badly
+type WebhookError struct {
+	Webhook string
+	Err     error
+}
+
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("admission webhook %s failed: %s", e.Webhook, e.Err)
+}
+
+func (e *WebhookError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	lock     sync.RWMutex
+	webhooks []Webhook
+	client   = &http.Client{}
+)
+
+// SetWebhooks checks the webhooks and replaces the current ones if they are all valid.
+// On error, the current webhooks are kept.
+func SetWebhooks(hooks []Webhook) error {
+	checked := make([]Webhook, 0, len(hooks))
+	for i, hook := range hooks {
+		if hook.Name == "" {
+			hook.Name = fmt.Sprintf("webhook-%d", i)
+		}
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid url of admission webhook %s: %q", hook.Name, hook.URL)
+		}
+		switch hook.FailurePolicy {
+		case "":
+			hook.FailurePolicy = FailurePolicyFail
+		case FailurePolicyFail, FailurePolicyIgnore:
+		default:
+			return fmt.Errorf("invalid failure policy of admission webhook %s: %q, expected %s or %s", hook.Name, hook.FailurePolicy, FailurePolicyFail, FailurePolicyIgnore)
+		}
+		if hook.Timeout <= 0 {
+			hook.Timeout = defaultTimeout
+		}
+		checked = append(checked, hook)
+	}
+
+	lock.Lock()
+	webhooks = checked
+	lock.Unlock()
+	return nil
+}
+
+// HasWebhooks returns true if some webhooks review the given operation on entity
+func HasWebhooks(entity, operation string) bool {
+	return len(getWebhooks(entity, operation)) > 0
+}
+
+func getWebhooks(entity, operation string) []Webhook {
+	lock.RLock()
+	defer lock.RUnlock()
+	var result []Webhook
+	for _, hook := range webhooks {
+		if hook.matches(entity, operation) {
+			result = append(result, hook)
+		}
+	}
+	return result
+}
+
+// Review submits the request to the matching webhooks, in order. The object mutated by a webhook is given to the next ones.
+// The review stops at the first denial. A WebhookError is returned when a webhook with the Fail policy fails.
+func Review(ctx context.Context, request model.AdmissionRequest) (*Result, error) {
+	result := &Result{
+		Allowed: true,
+		Object:  request.Object,
+	}
+	for _, hook := range getWebhooks(request.Entity, request.Operation) {
+		request.Object = result.Object
+		response, err := call(ctx, hook, request)
+		if err != nil {
+			if hook.FailurePolicy == FailurePolicyIgnore {
+				utils.GetLogger().WithError(err).WithField("webhook", hook.Name).Warn("admission webhook failed, ignoring it")
+				continue
+			}
+			return nil, &WebhookError{Webhook: hook.Name, Err: err}
+		}
+
+		if !response.Allowed {
+			result.Allowed = false
+			result.Message = response.Message
+			result.Webhook = hook.Name
+			return result, nil
+		}
+
+		if len(response.Patch) > 0 && request.Operation != model.AdmissionOperationDelete {
+			patched, err := applyPatch(result.Object, response.Patch)
+			if err != nil {
+				if hook.FailurePolicy == FailurePolicyIgnore {
+					utils.GetLogger().WithError(err).WithField("webhook", hook.Name).Warn("admission webhook patch cannot be applied, ignoring it")
+					continue
+				}
+				return nil, &WebhookError{Webhook: hook.Name, Err: err}
+			}
+			result.Object = patched
+		}
+	}
+	return result, nil
+}
+
+func call(ctx context.Context, hook Webhook, request model.AdmissionRequest) (_ *model.AdmissionResponse, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "admission webhook "+hook.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(http.MethodPost), semconv.URLFull(hook.URL)),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hook.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(httputils.HeaderNameContentType, httputils.HeaderValueApplicationJSONUTF8)
+	req.Header.Set(httputils.HeaderNameCorrelationID, request.UID)
+	tracing.Inject(ctx, req.Header)
+	for k, v := range hook.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	response := &model.AdmissionResponse{}
+	err = json.NewDecoder(resp.Body).Decode(response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return response, nil
+}
+
+func applyPatch(object json.RawMessage, operations []model.PatchOperation) (json.RawMessage, error) {
+	b, err := json.Marshal(operations)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := jsonpatch.DecodePatch(b)
+	if err != nil {
+		return nil, err
+	}
+	return patch.Apply(object)
+}
@@ -0,0 +1,255 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils"
+)
+
+func TestMain(m *testing.M) {
+	// the failures of the webhooks ignored are logged
+	utils.InitLogger("error", utils.LogFormatText)
+	os.Exit(m.Run())
+}
+
+// newWebhookServer starts a webhook answering with review, and returns its url
+func newWebhookServer(t *testing.T, review func(request model.AdmissionRequest) model.AdmissionResponse) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := model.AdmissionRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(review(request))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func setWebhooks(t *testing.T, hooks ...Webhook) {
+	t.Helper()
+	if err := SetWebhooks(hooks); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = SetWebhooks(nil)
+	})
+}
+
+func newRequest(operation string) model.AdmissionRequest {
+	return model.AdmissionRequest{
+		UID:       "uid",
+		Entity:    "template",
+		Operation: operation,
+		Object:    json.RawMessage(`{"name":"foo"}`),
+	}
+}
+
+func TestReviewAllowed(t *testing.T) {
+	var received model.AdmissionRequest
+	setWebhooks(t, Webhook{
+		Name: "allow",
+		URL: newWebhookServer(t, func(request model.AdmissionRequest) model.AdmissionResponse {
+			received = request
+			return model.AdmissionResponse{Allowed: true}
+		}),
+	})
+
+	result, err := Review(context.Background(), newRequest(model.AdmissionOperationCreate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed {
+		t.Errorf("the write is denied: %s", result.Message)
+	}
+	if string(result.Object) != `{"name":"foo"}` {
+		t.Errorf("the object is modified: %s", result.Object)
+	}
+	if received.UID != "uid" || received.Entity != "template" || received.Operation != model.AdmissionOperationCreate {
+		t.Errorf("unexpected request received by the webhook: %+v", received)
+	}
+}
+
+func TestReviewDenied(t *testing.T) {
+	called := false
+	setWebhooks(t,
+		Webhook{
+			Name: "deny",
+			URL: newWebhookServer(t, func(request model.AdmissionRequest) model.AdmissionResponse {
+				return model.AdmissionResponse{Allowed: false, Message: "names starting with foo are reserved"}
+			}),
+		},
+		Webhook{
+			Name: "next",
+			URL: newWebhookServer(t, func(request model.AdmissionRequest) model.AdmissionResponse {
+				called = true
+				return model.AdmissionResponse{Allowed: true}
+			}),
+		},
+	)
+
+	result, err := Review(context.Background(), newRequest(model.AdmissionOperationCreate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed {
+		t.Fatal("the write is allowed")
+	}
+	if result.Message != "names starting with foo are reserved" || result.Webhook != "deny" {
+		t.Errorf("unexpected denial: %+v", result)
+	}
+	if called {
+		t.Error("the review continued after the denial")
+	}
+}
+
+func TestReviewPatch(t *testing.T) {
+	var received json.RawMessage
+	setWebhooks(t,
+		Webhook{
+			Name: "mutate",
+			URL: newWebhookServer(t, func(request model.AdmissionRequest) model.AdmissionResponse {
+				return model.AdmissionResponse{
+					Allowed: true,
+					Patch:   []model.PatchOperation{{Op: "replace", Path: "/name", Value: "bar"}},
+				}
+			}),
+		},
+		Webhook{
+			Name: "next",
+			URL: newWebhookServer(t, func(request model.AdmissionRequest) model.AdmissionResponse {
+				received = request.Object
+				return model.AdmissionResponse{Allowed: true}
+			}),
+		},
+	)
+
+	result, err := Review(context.Background(), newRequest(model.AdmissionOperationUpdate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed {
+		t.Fatalf("the write is denied: %s", result.Message)
+	}
+	if string(result.Object) != `{"name":"bar"}` {
+		t.Errorf("the patch is not applied: %s", result.Object)
+	}
+	if string(received) != `{"name":"bar"}` {
+		t.Errorf("the next webhook did not receive the mutated object: %s", received)
+	}
+}
+
+func TestReviewTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run(FailurePolicyFail, func(t *testing.T) {
+		setWebhooks(t, Webhook{Name: "slow", URL: server.URL, Timeout: 50 * time.Millisecond, FailurePolicy: FailurePolicyFail})
+
+		_, err := Review(context.Background(), newRequest(model.AdmissionOperationCreate))
+		var webhookErr *WebhookError
+		if !errors.As(err, &webhookErr) {
+			t.Fatalf("a WebhookError is expected, got %v", err)
+		}
+		if webhookErr.Webhook != "slow" || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run(FailurePolicyIgnore, func(t *testing.T) {
+		setWebhooks(t, Webhook{Name: "slow", URL: server.URL, Timeout: 50 * time.Millisecond, FailurePolicy: FailurePolicyIgnore})
+
+		result, err := Review(context.Background(), newRequest(model.AdmissionOperationCreate))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed || string(result.Object) != `{"name":"foo"}` {
+			t.Errorf("the write is not admitted unchanged: %+v", result)
+		}
+	})
+}
+
+func TestReviewFailurePolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+	invalidPatch := newWebhookServer(t, func(request model.AdmissionRequest) model.AdmissionResponse {
+		return model.AdmissionResponse{
+			Allowed: true,
+			Patch:   []model.PatchOperation{{Op: "remove", Path: "/unknown"}},
+		}
+	})
+
+	tests := []struct {
+		name          string
+		url           string
+		failurePolicy string
+		admitted      bool
+	}{
+		{name: "error status with Fail policy", url: server.URL, failurePolicy: FailurePolicyFail},
+		{name: "error status with Ignore policy", url: server.URL, failurePolicy: FailurePolicyIgnore, admitted: true},
+		{name: "invalid patch with Fail policy", url: invalidPatch, failurePolicy: FailurePolicyFail},
+		{name: "invalid patch with Ignore policy", url: invalidPatch, failurePolicy: FailurePolicyIgnore, admitted: true},
+		{name: "default policy", url: server.URL},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			setWebhooks(t, Webhook{Name: "failing", URL: tt.url, FailurePolicy: tt.failurePolicy})
+
+			result, err := Review(context.Background(), newRequest(model.AdmissionOperationCreate))
+			if !tt.admitted {
+				var webhookErr *WebhookError
+				if !errors.As(err, &webhookErr) {
+					t.Fatalf("a WebhookError is expected, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Allowed || string(result.Object) != `{"name":"foo"}` {
+				t.Errorf("the write is not admitted unchanged: %+v", result)
+			}
+		})
+	}
+}
+
+func TestReviewMatchingWebhooks(t *testing.T) {
+	called := false
+	setWebhooks(t, Webhook{
+		Name: "deletions",
+		URL: newWebhookServer(t, func(request model.AdmissionRequest) model.AdmissionResponse {
+			called = true
+			return model.AdmissionResponse{}
+		}),
+		Entities:   []string{"template"},
+		Operations: []string{model.AdmissionOperationDelete},
+	})
+
+	if HasWebhooks("template", model.AdmissionOperationCreate) {
+		t.Error("the webhook matches the creations")
+	}
+	result, err := Review(context.Background(), newRequest(model.AdmissionOperationCreate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed || called {
+		t.Error("the creation is reviewed by the webhook of the deletions")
+	}
+}
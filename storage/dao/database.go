@@ -1,10 +1,34 @@
 package dao
 
 import (
+	"context"
+
 	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// HealthChecker is implemented by the dependencies of the api whose health is checked by the readiness endpoint
+type HealthChecker interface {
+	// CheckHealth returns an error when the dependency is not able to serve requests, or does not answer before
+	// the end of ctx
+	CheckHealth(ctx context.Context) error
+}
+
+// MetricsCollector is implemented by the databases exposing their own metrics, like the stats of their connection pool
+type MetricsCollector interface {
+	Collector() prometheus.Collector
+}
+
 type Database interface {
+	HealthChecker
+
+	// WithTransaction calls fn with a database making all its calls in a single transaction,
+	// committed when fn returns nil and rolled back otherwise
+	WithTransaction(fn func(tx Database) error) error
+
+	// Exists returns true if an entity of the given type (template) has the given value for field (as named in json),
+	// ignoring the entity whose id is excludedID. It is used by the validators checking uniqueness and references.
+	Exists(entity, field string, value interface{}, excludedID string) (bool, error)
 
 	// start: template dao funcs
 	GetAllTemplates() ([]*model.Template, error)
@@ -12,6 +36,16 @@ type Database interface {
 	CreateTemplate(*model.Template) error
 	DeleteTemplate(string) error
 	UpdateTemplate(template *model.Template) error
+	// bulk funcs return an error for each given item (nil on success), and an error not related to a specific item.
+	// When atomic is true, nothing is persisted if any item fails.
+	CreateTemplates(templates []*model.Template, atomic bool) ([]error, error)
+	UpdateTemplates(templates []*model.Template, atomic bool) ([]error, error)
+	DeleteTemplates(ids []string, atomic bool) ([]error, error)
 	// end: template dao funcs
 
+	// CreateIdempotencyRecord returns an ErrTypeDuplicate error if a non expired record already exists with the same key
+	CreateIdempotencyRecord(record *model.IdempotencyRecord) error
+	GetIdempotencyRecord(key string) (*model.IdempotencyRecord, error)
+	UpdateIdempotencyRecord(record *model.IdempotencyRecord) error
+	DeleteIdempotencyRecord(key string) error
 }
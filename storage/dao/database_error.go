@@ -10,6 +10,38 @@ const (
 	ErrTypeNotFound Type = iota
 	ErrTypeDuplicate
 	ErrTypeForeignKeyViolation
+	// ErrTypeTimeout is used when the database did not answer in time
+	ErrTypeTimeout
+	// ErrTypeUnavailable is used when the database cannot be reached
+	ErrTypeUnavailable
+	// ErrTypeConflict is used when the data has been modified concurrently (serialization failure, deadlock, write conflict)
+	ErrTypeConflict
+)
+
+var typeNames = map[Type]string{
+	ErrTypeNotFound:            "not_found",
+	ErrTypeDuplicate:           "duplicate",
+	ErrTypeForeignKeyViolation: "foreign_key_violation",
+	ErrTypeTimeout:             "timeout",
+	ErrTypeUnavailable:         "unavailable",
+	ErrTypeConflict:            "conflict",
+}
+
+func (t Type) String() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", int(t))
+}
+
+// Sentinel errors to be used with errors.Is, they match any DAOError of the same type
+var (
+	ErrNotFound            error = &DAOError{Type: ErrTypeNotFound}
+	ErrDuplicate           error = &DAOError{Type: ErrTypeDuplicate}
+	ErrForeignKeyViolation error = &DAOError{Type: ErrTypeForeignKeyViolation}
+	ErrTimeout             error = &DAOError{Type: ErrTypeTimeout}
+	ErrUnavailable         error = &DAOError{Type: ErrTypeUnavailable}
+	ErrConflict            error = &DAOError{Type: ErrTypeConflict}
 )
 
 type DAOError struct {
@@ -26,7 +58,18 @@ func NewDAOError(t Type, cause error) error {
 
 func (e *DAOError) Error() string {
 	if e.Cause != nil {
-		return fmt.Sprintf("Type %d: %s", e.Type, e.Cause.Error())
+		return fmt.Sprintf("Type %s: %s", e.Type, e.Cause.Error())
 	}
-	return fmt.Sprintf("Type %d: no cause given", e.Type)
+	return fmt.Sprintf("Type %s: no cause given", e.Type)
+}
+
+// Unwrap returns the cause of the error, to be used by errors.Is and errors.As
+func (e *DAOError) Unwrap() error {
+	return e.Cause
+}
+
+// Is makes errors.Is(err, dao.ErrNotFound) true for any DAOError of type ErrTypeNotFound
+func (e *DAOError) Is(target error) bool {
+	t, ok := target.(*DAOError)
+	return ok && t.Type == e.Type
 }
@@ -1,8 +1,10 @@
 package fake
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
+	"sync"
 
 	"github.com/coocood/freecache"
 	"github.com/denouche/go-api-skeleton/storage/dao"
@@ -15,7 +17,9 @@ const (
 )
 
 type DatabaseFake struct {
-	Cache *freecache.Cache
+	Cache           *freecache.Cache
+	idempotencyLock sync.Mutex
+	transactionLock sync.Mutex
 }
 
 func NewDatabaseFake(file string) dao.Database {
@@ -26,31 +30,50 @@ func NewDatabaseFake(file string) dao.Database {
 	if file != "" {
 		data, err := ioutil.ReadFile(file)
 		if err != nil {
-			utils.GetLogger().WithError(err).Error("error while loading data for in memory database")
+			utils.GetComponentLogger(utils.LoggerComponentDAO).WithError(err).Error("error while loading data for in memory database")
 		}
 
 		var export Export
 		err = json.Unmarshal(data, &export)
 		if err != nil {
-			utils.GetLogger().WithError(err).Error("error while reading data from file for in memory database")
+			utils.GetComponentLogger(utils.LoggerComponentDAO).WithError(err).Error("error while reading data from file for in memory database")
 		}
 
-		result.saveTemplates(export.Templates) // Template export
+		result.Import(&export)
 	}
 
 	return result
 }
 
+// CheckHealth always succeeds, the data being in memory
+func (db *DatabaseFake) CheckHealth(ctx context.Context) error {
+	return nil
+}
+
+// WithTransaction serializes the transactions, and restores the data as they were before fn if it fails.
+// The calls made outside of a transaction are not isolated from it.
+func (db *DatabaseFake) WithTransaction(fn func(tx dao.Database) error) error {
+	db.transactionLock.Lock()
+	defer db.transactionLock.Unlock()
+
+	snapshot := db.Export()
+	err := fn(db)
+	if err != nil {
+		db.Import(snapshot)
+	}
+	return err
+}
+
 func (db *DatabaseFake) save(key string, data []interface{}) {
 	b, err := json.Marshal(data)
 	if err != nil {
-		utils.GetLogger().WithError(err).Errorf("Error while marshal fake %s", key)
+		utils.GetComponentLogger(utils.LoggerComponentDAO).WithError(err).Errorf("Error while marshal fake %s", key)
 		_ = db.Cache.Set([]byte(key), []byte("[]"), 0)
 		return
 	}
 	err = db.Cache.Set([]byte(key), b, 0)
 	if err != nil {
-		utils.GetLogger().WithError(err).Errorf("Error while saving fake %s", key)
+		utils.GetComponentLogger(utils.LoggerComponentDAO).WithError(err).Errorf("Error while saving fake %s", key)
 	}
 }
 
@@ -58,6 +81,10 @@ type Export struct {
 	Templates []*model.Template // Template export
 }
 
+func (db *DatabaseFake) Import(export *Export) {
+	db.saveTemplates(export.Templates) // Template export
+}
+
 func (db *DatabaseFake) Export() *Export {
 	return &Export{
 		Templates: db.loadTemplates(), // Template export
@@ -0,0 +1,65 @@
+package fake
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/storage/dao"
+	"github.com/denouche/go-api-skeleton/storage/model"
+)
+
+const (
+	cacheKeyIdempotencyPrefix = "idempotency:"
+)
+
+func (db *DatabaseFake) saveIdempotencyRecord(record *model.IdempotencyRecord) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	ttl := int(time.Until(record.ExpiresAt).Seconds())
+	if ttl <= 0 {
+		ttl = 1
+	}
+	return db.Cache.Set([]byte(cacheKeyIdempotencyPrefix+record.Key), b, ttl)
+}
+
+func (db *DatabaseFake) CreateIdempotencyRecord(record *model.IdempotencyRecord) error {
+	// the lock makes the existence check and the creation atomic, to handle concurrent requests with the same key
+	db.idempotencyLock.Lock()
+	defer db.idempotencyLock.Unlock()
+
+	if _, err := db.Cache.Get([]byte(cacheKeyIdempotencyPrefix + record.Key)); err == nil {
+		return dao.NewDAOError(dao.ErrTypeDuplicate, errors.New("idempotency record already exists"))
+	}
+	return db.saveIdempotencyRecord(record)
+}
+
+func (db *DatabaseFake) GetIdempotencyRecord(key string) (*model.IdempotencyRecord, error) {
+	b, err := db.Cache.Get([]byte(cacheKeyIdempotencyPrefix + key))
+	if err != nil {
+		return nil, dao.NewDAOError(dao.ErrTypeNotFound, errors.New("idempotency record not found"))
+	}
+	record := &model.IdempotencyRecord{}
+	err = json.Unmarshal(b, record)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (db *DatabaseFake) UpdateIdempotencyRecord(record *model.IdempotencyRecord) error {
+	db.idempotencyLock.Lock()
+	defer db.idempotencyLock.Unlock()
+
+	return db.saveIdempotencyRecord(record)
+}
+
+func (db *DatabaseFake) DeleteIdempotencyRecord(key string) error {
+	db.idempotencyLock.Lock()
+	defer db.idempotencyLock.Unlock()
+
+	db.Cache.Del([]byte(cacheKeyIdempotencyPrefix + key))
+	return nil
+}
@@ -0,0 +1,40 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// lookups gives, for each entity, the func loading all the entities of this type
+var lookups = map[string]func(db *DatabaseFake) interface{}{
+	lookupEntityTemplate: func(db *DatabaseFake) interface{} { return db.loadTemplates() }, // Template lookup
+}
+
+func (db *DatabaseFake) Exists(entity, field string, value interface{}, excludedID string) (bool, error) {
+	load, ok := lookups[entity]
+	if !ok {
+		return false, fmt.Errorf("unknown entity %s", entity)
+	}
+
+	// the entities are compared through their json representation, as the fields are named in json
+	b, err := json.Marshal(load(db))
+	if err != nil {
+		return false, err
+	}
+	var items []map[string]interface{}
+	err = json.Unmarshal(b, &items)
+	if err != nil {
+		return false, err
+	}
+
+	expected := fmt.Sprint(value)
+	for _, item := range items {
+		if excludedID != "" && fmt.Sprint(item["id"]) == excludedID {
+			continue
+		}
+		if v, ok := item[field]; ok && fmt.Sprint(v) == expected {
+			return true, nil
+		}
+	}
+	return false, nil
+}
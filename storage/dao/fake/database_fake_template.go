@@ -12,7 +12,8 @@ import (
 )
 
 const (
-	cacheKeyTemplates = "templates"
+	cacheKeyTemplates    = "templates"
+	lookupEntityTemplate = "template"
 )
 
 func (db *DatabaseFake) saveTemplates(templates []*model.Template) {
@@ -31,7 +32,7 @@ func (db *DatabaseFake) loadTemplates() []*model.Template {
 	}
 	err = json.Unmarshal(b, &templates)
 	if err != nil {
-		utils.GetLogger().WithError(err).Error("Error while unmarshal fake templates")
+		utils.GetComponentLogger(utils.LoggerComponentDAO).WithError(err).Error("Error while unmarshal fake templates")
 	}
 	return templates
 }
@@ -94,3 +95,76 @@ func (db *DatabaseFake) UpdateTemplate(template *model.Template) error {
 	*template = *foundTemplate
 	return nil
 }
+
+// bulk funcs load and save the templates only once for the whole batch
+
+func (db *DatabaseFake) CreateTemplates(templatesToCreate []*model.Template, atomic bool) ([]error, error) {
+	errs := make([]error, len(templatesToCreate))
+	templates := db.loadTemplates()
+	for _, template := range templatesToCreate {
+		template.ID = uuid.NewV4().String()
+		template.CreatedAt = time.Now()
+		templates = append(templates, template)
+	}
+	db.saveTemplates(templates)
+	return errs, nil
+}
+
+func (db *DatabaseFake) UpdateTemplates(templatesToUpdate []*model.Template, atomic bool) ([]error, error) {
+	errs := make([]error, len(templatesToUpdate))
+	failed := false
+	templates := db.loadTemplates()
+	byID := make(map[string]*model.Template, len(templates))
+	for _, u := range templates {
+		byID[u.ID] = u
+	}
+
+	now := time.Now()
+	for i, template := range templatesToUpdate {
+		foundTemplate, ok := byID[template.ID]
+		if !ok {
+			errs[i] = dao.NewDAOError(dao.ErrTypeNotFound, errors.New("template not found"))
+			failed = true
+			continue
+		}
+		foundTemplate.TemplateEditable = template.TemplateEditable
+		foundTemplate.UpdatedAt = &now
+		*template = *foundTemplate
+	}
+
+	if !atomic || !failed {
+		db.saveTemplates(templates)
+	}
+	return errs, nil
+}
+
+func (db *DatabaseFake) DeleteTemplates(ids []string, atomic bool) ([]error, error) {
+	errs := make([]error, len(ids))
+	failed := false
+	templates := db.loadTemplates()
+	toDelete := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		toDelete[id] = true
+	}
+
+	newTemplates := make([]*model.Template, 0)
+	found := make(map[string]bool, len(ids))
+	for _, u := range templates {
+		if toDelete[u.ID] {
+			found[u.ID] = true
+			continue
+		}
+		newTemplates = append(newTemplates, u)
+	}
+	for i, id := range ids {
+		if !found[id] {
+			errs[i] = dao.NewDAOError(dao.ErrTypeNotFound, errors.New("template not found"))
+			failed = true
+		}
+	}
+
+	if !atomic || !failed {
+		db.saveTemplates(newTemplates)
+	}
+	return errs, nil
+}
@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"context"
+
 	"github.com/denouche/go-api-skeleton/storage/dao"
 	"github.com/stretchr/testify/mock"
 )
@@ -12,3 +14,18 @@ type DatabaseMock struct {
 func NewDatabaseMock() dao.Database {
 	return &DatabaseMock{}
 }
+
+func (db *DatabaseMock) CheckHealth(ctx context.Context) error {
+	args := db.Called(ctx)
+	return args.Error(0)
+}
+
+func (db *DatabaseMock) WithTransaction(fn func(tx dao.Database) error) error {
+	args := db.Called(fn)
+	return args.Error(0)
+}
+
+func (db *DatabaseMock) Exists(entity, field string, value interface{}, excludedID string) (bool, error) {
+	args := db.Called(entity, field, value, excludedID)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"github.com/denouche/go-api-skeleton/storage/model"
+)
+
+func (db *DatabaseMock) CreateIdempotencyRecord(record *model.IdempotencyRecord) error {
+	args := db.Called(record)
+	return args.Error(0)
+}
+
+func (db *DatabaseMock) GetIdempotencyRecord(key string) (*model.IdempotencyRecord, error) {
+	args := db.Called(key)
+	return args.Get(0).(*model.IdempotencyRecord), args.Error(1)
+}
+
+func (db *DatabaseMock) UpdateIdempotencyRecord(record *model.IdempotencyRecord) error {
+	args := db.Called(record)
+	return args.Error(0)
+}
+
+func (db *DatabaseMock) DeleteIdempotencyRecord(key string) error {
+	args := db.Called(key)
+	return args.Error(0)
+}
@@ -28,3 +28,18 @@ func (db *DatabaseMock) UpdateTemplate(template *model.Template) error {
 	args := db.Called(template)
 	return args.Error(0)
 }
+
+func (db *DatabaseMock) CreateTemplates(templates []*model.Template, atomic bool) ([]error, error) {
+	args := db.Called(templates, atomic)
+	return args.Get(0).([]error), args.Error(1)
+}
+
+func (db *DatabaseMock) UpdateTemplates(templates []*model.Template, atomic bool) ([]error, error) {
+	args := db.Called(templates, atomic)
+	return args.Get(0).([]error), args.Error(1)
+}
+
+func (db *DatabaseMock) DeleteTemplates(ids []string, atomic bool) ([]error, error) {
+	args := db.Called(ids, atomic)
+	return args.Get(0).([]error), args.Error(1)
+}
@@ -2,6 +2,9 @@ package mongodb
 
 import (
 	"context"
+	"errors"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/denouche/go-api-skeleton/storage/dao"
@@ -9,16 +12,23 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
 )
 
 const (
 	mongoWriteErrorDuplicate      = 11000
 	mongoWriteErrorDuplicateOther = 11001
+	mongoErrorWriteConflict       = 112
+
+	mongoLabelTransientTransactionError = "TransientTransactionError"
+	mongoServerSelectionErrorPrefix     = "server selection error"
 )
 
 type DatabaseMongoDB struct {
 	client       *mongo.Client
 	databaseName string
+	// sessionCtx is set when the database is bound to a transaction
+	sessionCtx mongo.SessionContext
 }
 
 func handleWriteException(e mongo.WriteException) error {
@@ -33,18 +43,62 @@ func handleWriteException(e mongo.WriteException) error {
 	return e
 }
 
+// handleMongoError converts the errors returned by the driver to DAOErrors when the cause is known
+func handleMongoError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var daoErr *dao.DAOError
+	if errors.As(err, &daoErr) {
+		return err
+	}
+
+	switch e := err.(type) {
+	case mongo.WriteException:
+		return handleWriteException(e)
+	case mongo.CommandError:
+		switch {
+		case e.IsMaxTimeMSExpiredError():
+			return dao.NewDAOError(dao.ErrTypeTimeout, e)
+		case e.Code == mongoErrorWriteConflict, e.HasErrorLabel(mongoLabelTransientTransactionError):
+			return dao.NewDAOError(dao.ErrTypeConflict, e)
+		}
+		return e
+	case topology.ConnectionError:
+		return dao.NewDAOError(dao.ErrTypeUnavailable, e)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return dao.NewDAOError(dao.ErrTypeTimeout, err)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return dao.NewDAOError(dao.ErrTypeTimeout, err)
+		}
+		return dao.NewDAOError(dao.ErrTypeUnavailable, err)
+	}
+	// the driver does not wrap the server selection errors, they can only be identified by their message
+	if strings.HasPrefix(err.Error(), mongoServerSelectionErrorPrefix) {
+		return dao.NewDAOError(dao.ErrTypeUnavailable, err)
+	}
+	return err
+}
+
 func NewDatabaseMongoDB(connectionURI, dbName string) dao.Database {
-	ctx, _ := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	client, err := mongo.Connect(ctx, options.Client().ApplyURI(connectionURI))
+	cancel()
 	if err != nil {
-		utils.GetLogger().WithError(err).Fatal("Unable to get a connection to mongodb")
+		utils.GetComponentLogger(utils.LoggerComponentDAO).WithError(err).Fatal("Unable to get a connection to mongodb")
 	}
 
 	for {
+		ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
 		err = client.Ping(ctx, readpref.Primary())
-		ctx, _ = context.WithTimeout(context.Background(), 2*time.Second)
+		cancel()
 		if err != nil {
-			utils.GetLogger().WithError(err).Error("Unable to ping mongodb, waiting 2s before retrying...")
+			utils.GetComponentLogger(utils.LoggerComponentDAO).WithError(err).Error("Unable to ping mongodb, waiting 2s before retrying...")
 			time.Sleep(2 * time.Second)
 			continue
 		}
@@ -57,6 +111,7 @@ func NewDatabaseMongoDB(connectionURI, dbName string) dao.Database {
 	}
 
 	result.populateTemplateIndexes() // Template index
+	result.populateIdempotencyRecordIndexes()
 
 	return result
 }
@@ -64,7 +119,44 @@ func NewDatabaseMongoDB(connectionURI, dbName string) dao.Database {
 func (db *DatabaseMongoDB) getSession() *mongo.Database {
 	return db.client.Database(db.databaseName)
 }
-func (db *DatabaseMongoDB) getCtx() context.Context {
-	ctx, _ := context.WithTimeout(context.Background(), 30*time.Second)
-	return ctx
+
+// getCtx returns the context of the calls to mongodb, the cancel func must be called once the call is done
+func (db *DatabaseMongoDB) getCtx() (context.Context, context.CancelFunc) {
+	if db.sessionCtx != nil {
+		return db.sessionCtx, func() {}
+	}
+	return context.WithTimeout(context.Background(), 30*time.Second)
+}
+
+// CheckHealth pings the primary of mongodb
+func (db *DatabaseMongoDB) CheckHealth(ctx context.Context) error {
+	return handleMongoError(db.client.Ping(ctx, readpref.Primary()))
+}
+
+// WithTransaction makes the calls of fn in a mongodb transaction, which requires mongodb to run as a replica set
+func (db *DatabaseMongoDB) WithTransaction(fn func(tx dao.Database) error) error {
+	if db.sessionCtx != nil {
+		// already in a transaction, the outer one will be committed or aborted
+		return fn(db)
+	}
+
+	ctx, cancel := db.getCtx()
+	defer cancel()
+	err := db.client.UseSession(ctx, func(sc mongo.SessionContext) error {
+		err := sc.StartTransaction()
+		if err != nil {
+			return err
+		}
+		err = fn(&DatabaseMongoDB{
+			client:       db.client,
+			databaseName: db.databaseName,
+			sessionCtx:   sc,
+		})
+		if err != nil {
+			_ = sc.AbortTransaction(sc)
+			return err
+		}
+		return sc.CommitTransaction(sc)
+	})
+	return handleMongoError(err)
 }
@@ -0,0 +1,114 @@
+package mongodb
+
+import (
+	"errors"
+
+	"github.com/denouche/go-api-skeleton/storage/dao"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// bulkWrite executes the write models in a single BulkWrite, and returns an error for each of the count batch items.
+// indexes gives the batch item index of each write model.
+func (db *DatabaseMongoDB) bulkWrite(collectionName string, models []mongo.WriteModel, indexes []int, count int, atomic bool) ([]error, error) {
+	errs := make([]error, count)
+	if len(models) == 0 {
+		return errs, nil
+	}
+
+	ctx, cancel := db.getCtx()
+	defer cancel()
+	collection := db.getSession().Collection(collectionName)
+	var err error
+	if atomic && db.sessionCtx != nil {
+		// already in a transaction, an error makes the whole transaction aborted
+		_, err = collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(true))
+	} else if atomic {
+		err = db.client.UseSession(ctx, func(sc mongo.SessionContext) error {
+			if err := sc.StartTransaction(); err != nil {
+				return err
+			}
+			_, err := collection.BulkWrite(sc, models, options.BulkWrite().SetOrdered(true))
+			if err != nil {
+				_ = sc.AbortTransaction(sc)
+				return err
+			}
+			return sc.CommitTransaction(sc)
+		})
+	} else {
+		_, err = collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	}
+
+	if bwe, ok := err.(mongo.BulkWriteException); ok && len(bwe.WriteErrors) > 0 {
+		for _, we := range bwe.WriteErrors {
+			errs[indexes[we.Index]] = handleWriteException(mongo.WriteException{WriteErrors: mongo.WriteErrors{we.WriteError}})
+		}
+		return errs, nil
+	}
+	return errs, handleMongoError(err)
+}
+
+// checkExist returns a not found error for each of the given ids missing in the collection
+func (db *DatabaseMongoDB) checkExist(collectionName string, ids []string) ([]error, error) {
+	errs := make([]error, len(ids))
+	ctx, cancel := db.getCtx()
+	defer cancel()
+	cur, err := db.getSession().Collection(collectionName).
+		Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, handleMongoError(err)
+	}
+	defer cur.Close(ctx)
+
+	found := make(map[string]bool, len(ids))
+	for cur.Next(ctx) {
+		var result struct {
+			ID string `bson:"_id"`
+		}
+		if err := cur.Decode(&result); err != nil {
+			return nil, handleMongoError(err)
+		}
+		found[result.ID] = true
+	}
+	if err := cur.Err(); err != nil {
+		return nil, handleMongoError(err)
+	}
+
+	for i, id := range ids {
+		if !found[id] {
+			errs[i] = dao.NewDAOError(dao.ErrTypeNotFound, errors.New("document not found"))
+		}
+	}
+	return errs, nil
+}
+
+func hasError(errs []error) bool {
+	for _, err := range errs {
+		if err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeErrors returns the first non nil error of each item
+func mergeErrors(errs, others []error) []error {
+	for i := range errs {
+		if errs[i] == nil && i < len(others) {
+			errs[i] = others[i]
+		}
+	}
+	return errs
+}
+
+// toBsonM converts a document to a bson.M, to be able to add fields to it
+func toBsonM(document interface{}) (bson.M, error) {
+	b, err := bson.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	result := bson.M{}
+	err = bson.Unmarshal(b, &result)
+	return result, err
+}
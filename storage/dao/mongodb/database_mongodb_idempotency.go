@@ -0,0 +1,93 @@
+package mongodb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/storage/dao"
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const (
+	collectionIdempotencyKeyName = "idempotency_key"
+)
+
+func (db *DatabaseMongoDB) populateIdempotencyRecordIndexes() {
+	ctx, cancel := db.getCtx()
+	defer cancel()
+	// expired records are removed by mongodb in background
+	var expireAfterSeconds int32
+	_, err := db.getSession().Collection(collectionIdempotencyKeyName).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bsonx.Doc{{Key: "expiresAt", Value: bsonx.Int32(1)}},
+		Options: &options.IndexOptions{
+			ExpireAfterSeconds: &expireAfterSeconds,
+		},
+	})
+	if err != nil {
+		utils.GetComponentLogger(utils.LoggerComponentDAO).WithError(err).Error("error while creating mongodb index")
+	}
+}
+
+func (db *DatabaseMongoDB) CreateIdempotencyRecord(record *model.IdempotencyRecord) error {
+	ctx, cancel := db.getCtx()
+	defer cancel()
+	_, err := db.getSession().Collection(collectionIdempotencyKeyName).InsertOne(ctx, record)
+	if ce, ok := err.(mongo.WriteException); ok {
+		err = handleWriteException(ce)
+		if e, ok := err.(*dao.DAOError); ok && e.Type == dao.ErrTypeDuplicate {
+			// the background removal of expired records is not immediate, replace the existing record if it is expired
+			r, errReplace := db.getSession().Collection(collectionIdempotencyKeyName).
+				ReplaceOne(ctx, bson.M{"_id": record.Key, "expiresAt": bson.M{"$lte": time.Now()}}, record)
+			if errReplace != nil {
+				return errReplace
+			}
+			if r.MatchedCount == 0 {
+				return dao.NewDAOError(dao.ErrTypeDuplicate, errors.New("idempotency record already exists"))
+			}
+			return nil
+		}
+	}
+	return handleMongoError(err)
+}
+
+func (db *DatabaseMongoDB) GetIdempotencyRecord(key string) (*model.IdempotencyRecord, error) {
+	ctx, cancel := db.getCtx()
+	defer cancel()
+	var result *model.IdempotencyRecord
+	err := db.getSession().Collection(collectionIdempotencyKeyName).
+		FindOne(ctx, bson.M{"_id": key, "expiresAt": bson.M{"$gt": time.Now()}}).
+		Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return nil, dao.NewDAOError(dao.ErrTypeNotFound, err)
+	}
+	if err != nil {
+		return nil, handleMongoError(err)
+	}
+
+	return result, nil
+}
+
+func (db *DatabaseMongoDB) UpdateIdempotencyRecord(record *model.IdempotencyRecord) error {
+	ctx, cancel := db.getCtx()
+	defer cancel()
+	r, err := db.getSession().Collection(collectionIdempotencyKeyName).ReplaceOne(ctx, bson.M{"_id": record.Key}, record)
+	if err != nil {
+		return handleMongoError(err)
+	}
+	if r.MatchedCount == 0 {
+		return dao.NewDAOError(dao.ErrTypeNotFound, err)
+	}
+	return nil
+}
+
+func (db *DatabaseMongoDB) DeleteIdempotencyRecord(key string) error {
+	ctx, cancel := db.getCtx()
+	defer cancel()
+	_, err := db.getSession().Collection(collectionIdempotencyKeyName).DeleteOne(ctx, bson.M{"_id": key})
+	return handleMongoError(err)
+}
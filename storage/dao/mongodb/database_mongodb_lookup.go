@@ -0,0 +1,47 @@
+package mongodb
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// lookupCollection describes the collection of an entity, and the document field of each field which can be looked up
+type lookupCollection struct {
+	entity     string
+	collection string
+	fields     map[string]string
+}
+
+var lookupCollections = map[string]lookupCollection{
+	lookupCollectionTemplate.entity: lookupCollectionTemplate, // Template lookup
+}
+
+func (db *DatabaseMongoDB) Exists(entity, field string, value interface{}, excludedID string) (bool, error) {
+	c, ok := lookupCollections[entity]
+	if !ok {
+		return false, fmt.Errorf("unknown entity %s", entity)
+	}
+	documentField, ok := c.fields[field]
+	if !ok {
+		return false, fmt.Errorf("field %s of entity %s cannot be looked up", field, entity)
+	}
+
+	filter := bson.M{documentField: value}
+	if excludedID != "" {
+		if documentField == "_id" {
+			filter["_id"] = bson.M{"$eq": value, "$ne": excludedID}
+		} else {
+			filter["_id"] = bson.M{"$ne": excludedID}
+		}
+	}
+
+	ctx, cancel := db.getCtx()
+	defer cancel()
+	count, err := db.getSession().Collection(c.collection).CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, handleMongoError(err)
+	}
+	return count > 0, nil
+}
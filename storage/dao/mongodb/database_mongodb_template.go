@@ -17,8 +17,18 @@ const (
 	collectionTemplateName = "template"
 )
 
+var lookupCollectionTemplate = lookupCollection{
+	entity:     "template",
+	collection: collectionTemplateName,
+	fields: map[string]string{
+		"id":   "_id",
+		"name": "name",
+	},
+}
+
 func (db *DatabaseMongoDB) populateTemplateIndexes() {
-	ctx := db.getCtx()
+	ctx, cancel := db.getCtx()
+	defer cancel()
 	_, err := db.getSession().Collection(collectionTemplateName).Indexes().CreateOne(ctx, mongo.IndexModel{
 		Keys: bsonx.Doc{{Key: "name", Value: bsonx.Int32(1)}},
 		Options: &options.IndexOptions{
@@ -26,15 +36,16 @@ func (db *DatabaseMongoDB) populateTemplateIndexes() {
 		},
 	})
 	if err != nil {
-		utils.GetLogger().WithError(err).Error("error while creating mongodb index")
+		utils.GetComponentLogger(utils.LoggerComponentDAO).WithError(err).Error("error while creating mongodb index")
 	}
 }
 
 func (db *DatabaseMongoDB) GetAllTemplates() ([]*model.Template, error) {
-	ctx := db.getCtx()
+	ctx, cancel := db.getCtx()
+	defer cancel()
 	cur, err := db.getSession().Collection(collectionTemplateName).Find(ctx, bson.D{})
 	if err != nil {
-		return nil, err
+		return nil, handleMongoError(err)
 	}
 	defer cur.Close(ctx)
 
@@ -43,26 +54,27 @@ func (db *DatabaseMongoDB) GetAllTemplates() ([]*model.Template, error) {
 		var result *model.Template
 		err := cur.Decode(&result)
 		if err != nil {
-			return nil, err
+			return nil, handleMongoError(err)
 		}
 		results = append(results, result)
 	}
 	if err := cur.Err(); err != nil {
-		return nil, err
+		return nil, handleMongoError(err)
 	}
 
 	return results, nil
 }
 
 func (db *DatabaseMongoDB) GetTemplateByID(id string) (*model.Template, error) {
-	ctx := db.getCtx()
+	ctx, cancel := db.getCtx()
+	defer cancel()
 	var result *model.Template
 	err := db.getSession().Collection(collectionTemplateName).FindOne(ctx, bson.M{"_id": id}).Decode(&result)
 	if err == mongo.ErrNoDocuments {
 		return nil, dao.NewDAOError(dao.ErrTypeNotFound, err)
 	}
 	if err != nil {
-		return nil, err
+		return nil, handleMongoError(err)
 	}
 
 	return result, nil
@@ -72,34 +84,134 @@ func (db *DatabaseMongoDB) CreateTemplate(template *model.Template) error {
 	template.ID = primitive.NewObjectID().Hex()
 	template.CreatedAt = time.Now()
 
-	ctx := db.getCtx()
+	ctx, cancel := db.getCtx()
+	defer cancel()
 	_, err := db.getSession().Collection(collectionTemplateName).InsertOne(ctx, template)
-	if ce, ok := err.(mongo.WriteException); ok {
-		return handleWriteException(ce)
-	}
-	return err
+	return handleMongoError(err)
 }
 
 func (db *DatabaseMongoDB) DeleteTemplate(id string) error {
-	ctx := db.getCtx()
+	ctx, cancel := db.getCtx()
+	defer cancel()
 	_, err := db.getSession().Collection(collectionTemplateName).DeleteOne(ctx, bson.M{"_id": id})
 	if err == mongo.ErrNoDocuments {
 		return dao.NewDAOError(dao.ErrTypeNotFound, err)
 	}
-	return err
+	return handleMongoError(err)
 }
 
 func (db *DatabaseMongoDB) UpdateTemplate(template *model.Template) error {
 	now := time.Now()
 	template.UpdatedAt = &now
 
-	ctx := db.getCtx()
+	ctx, cancel := db.getCtx()
+	defer cancel()
 	r, err := db.getSession().Collection(collectionTemplateName).ReplaceOne(ctx, bson.M{"_id": template.ID}, template)
 	if err != nil {
-		return err
+		return handleMongoError(err)
 	}
 	if r.MatchedCount == 0 {
 		return dao.NewDAOError(dao.ErrTypeNotFound, err)
 	}
 	return nil
 }
+
+// CreateTemplates inserts all the templates with a single BulkWrite.
+// When atomic is true, the BulkWrite is made in a transaction, which requires mongodb to run as a replica set.
+func (db *DatabaseMongoDB) CreateTemplates(templates []*model.Template, atomic bool) ([]error, error) {
+	models := make([]mongo.WriteModel, 0, len(templates))
+	for _, template := range templates {
+		template.ID = primitive.NewObjectID().Hex()
+		template.CreatedAt = time.Now()
+		models = append(models, mongo.NewInsertOneModel().SetDocument(template))
+	}
+	indexes := make([]int, len(templates))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return db.bulkWrite(collectionTemplateName, models, indexes, len(templates), atomic)
+}
+
+// UpdateTemplates updates all the templates with a single BulkWrite, after having checked they exist.
+// When atomic is true, the BulkWrite is made in a transaction, which requires mongodb to run as a replica set.
+func (db *DatabaseMongoDB) UpdateTemplates(templates []*model.Template, atomic bool) ([]error, error) {
+	ids := make([]string, 0, len(templates))
+	for _, template := range templates {
+		ids = append(ids, template.ID)
+	}
+	errs, err := db.checkExist(collectionTemplateName, ids)
+	if err != nil || (atomic && hasError(errs)) {
+		return errs, err
+	}
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, 0, len(templates))
+	indexes := make([]int, 0, len(templates))
+	for i, template := range templates {
+		if errs[i] != nil {
+			continue
+		}
+		// only the editable fields are set, to keep the creation date
+		set, err := toBsonM(template.TemplateEditable)
+		if err != nil {
+			return nil, handleMongoError(err)
+		}
+		set["updatedAt"] = now
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": template.ID}).SetUpdate(bson.M{"$set": set}))
+		indexes = append(indexes, i)
+	}
+	bulkErrs, err := db.bulkWrite(collectionTemplateName, models, indexes, len(templates), atomic)
+	if err != nil {
+		return nil, handleMongoError(err)
+	}
+	errs = mergeErrors(errs, bulkErrs)
+
+	// read back the updated templates to return them entirely
+	ctx, cancel := db.getCtx()
+	defer cancel()
+	cur, err := db.getSession().Collection(collectionTemplateName).Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, handleMongoError(err)
+	}
+	defer cur.Close(ctx)
+
+	updated := make(map[string]*model.Template, len(templates))
+	for cur.Next(ctx) {
+		var result *model.Template
+		if err := cur.Decode(&result); err != nil {
+			return nil, handleMongoError(err)
+		}
+		updated[result.ID] = result
+	}
+	if err := cur.Err(); err != nil {
+		return nil, handleMongoError(err)
+	}
+
+	for i, template := range templates {
+		if u, ok := updated[template.ID]; ok && errs[i] == nil {
+			*template = *u
+		}
+	}
+	return errs, nil
+}
+
+// DeleteTemplates deletes all the templates with a single BulkWrite, after having checked they exist.
+// When atomic is true, the BulkWrite is made in a transaction, which requires mongodb to run as a replica set.
+func (db *DatabaseMongoDB) DeleteTemplates(ids []string, atomic bool) ([]error, error) {
+	errs, err := db.checkExist(collectionTemplateName, ids)
+	if err != nil || (atomic && hasError(errs)) {
+		return errs, err
+	}
+
+	models := make([]mongo.WriteModel, 0, len(ids))
+	indexes := make([]int, 0, len(ids))
+	for i, id := range ids {
+		if errs[i] != nil {
+			continue
+		}
+		models = append(models, mongo.NewDeleteOneModel().SetFilter(bson.M{"_id": id}))
+		indexes = append(indexes, i)
+	}
+	bulkErrs, err := db.bulkWrite(collectionTemplateName, models, indexes, len(ids), atomic)
+	return mergeErrors(errs, bulkErrs), err
+}
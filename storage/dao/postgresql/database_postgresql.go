@@ -1,41 +1,151 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
 
 	"github.com/denouche/go-api-skeleton/storage/dao"
 	"github.com/denouche/go-api-skeleton/utils"
 	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 const (
-	pgCodeUniqueViolation     = "23505"
-	pgCodeForeingKeyViolation = "23503"
+	pgCodeUniqueViolation      = "23505"
+	pgCodeForeingKeyViolation  = "23503"
+	pgCodeSerializationFailure = "40001"
+	pgCodeDeadlockDetected     = "40P01"
+	pgCodeQueryCanceled        = "57014"
+	pgCodeTooManyConnections   = "53300"
+	pgCodeAdminShutdown        = "57P01"
+	pgCodeCannotConnectNow     = "57P03"
+	pgClassConnectionException = "08"
 )
 
-func handlePgError(e *pq.Error) error {
-	if e.Code == pgCodeUniqueViolation {
-		return dao.NewDAOError(dao.ErrTypeDuplicate, e)
+// handlePgError converts the errors returned by the driver to DAOErrors when the cause is known
+func handlePgError(err error) error {
+	if e, ok := err.(*pq.Error); ok {
+		switch {
+		case e.Code == pgCodeUniqueViolation:
+			return dao.NewDAOError(dao.ErrTypeDuplicate, e)
+		case e.Code == pgCodeForeingKeyViolation:
+			return dao.NewDAOError(dao.ErrTypeForeignKeyViolation, e)
+		case e.Code == pgCodeSerializationFailure, e.Code == pgCodeDeadlockDetected:
+			return dao.NewDAOError(dao.ErrTypeConflict, e)
+		case e.Code == pgCodeQueryCanceled:
+			return dao.NewDAOError(dao.ErrTypeTimeout, e)
+		case e.Code == pgCodeTooManyConnections, e.Code == pgCodeAdminShutdown, e.Code == pgCodeCannotConnectNow,
+			e.Code.Class() == pgClassConnectionException:
+			return dao.NewDAOError(dao.ErrTypeUnavailable, e)
+		}
+		return e
 	}
 
-	if e.Code == pgCodeForeingKeyViolation {
-		return dao.NewDAOError(dao.ErrTypeForeignKeyViolation, e)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return dao.NewDAOError(dao.ErrTypeTimeout, err)
 	}
-	return e
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return dao.NewDAOError(dao.ErrTypeTimeout, err)
+		}
+		return dao.NewDAOError(dao.ErrTypeUnavailable, err)
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return dao.NewDAOError(dao.ErrTypeUnavailable, err)
+	}
+	return err
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
 type DatabasePostgreSQL struct {
-	session *sql.DB
+	db *sql.DB
+	tx *sql.Tx
+	// session is the transaction when the database is bound to one, the db otherwise
+	session querier
 }
 
 func NewDatabasePostgreSQL(connectionURI string) dao.Database {
 	db, err := sql.Open("postgres", connectionURI)
 	if err != nil {
-		utils.GetLogger().WithError(err).Fatal("Unable to get a connection to the postgres db")
+		utils.GetComponentLogger(utils.LoggerComponentDAO).WithError(err).Fatal("Unable to get a connection to the postgres db")
 	}
 	err = db.Ping()
 	if err != nil {
-		utils.GetLogger().WithError(err).Fatal("Unable to ping the postgres db")
+		utils.GetComponentLogger(utils.LoggerComponentDAO).WithError(err).Fatal("Unable to ping the postgres db")
+	}
+	return &DatabasePostgreSQL{db: db, session: db}
+}
+
+// CheckHealth pings the postgres db
+func (db *DatabasePostgreSQL) CheckHealth(ctx context.Context) error {
+	return handlePgError(db.db.PingContext(ctx))
+}
+
+// Collector returns the collector of the stats of the connection pool, as go_sql_* metrics labelled db_name="postgresql"
+func (db *DatabasePostgreSQL) Collector() prometheus.Collector {
+	return collectors.NewDBStatsCollector(db.db, "postgresql")
+}
+
+func (db *DatabasePostgreSQL) WithTransaction(fn func(tx dao.Database) error) error {
+	if db.tx != nil {
+		// already in a transaction, the outer one will be committed or rolled back
+		return fn(db)
+	}
+
+	tx, err := db.db.Begin()
+	if err != nil {
+		return handlePgError(err)
+	}
+	err = fn(&DatabasePostgreSQL{db: db.db, tx: tx, session: tx})
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return handlePgError(tx.Commit())
+}
+
+// runInTransaction runs fn in a transaction, or in a savepoint when the database is already bound to a transaction.
+// The changes made by fn are rolled back when it returns an error or when it returns commit as false.
+func (db *DatabasePostgreSQL) runInTransaction(fn func(q querier) (commit bool, err error)) error {
+	if db.tx != nil {
+		_, err := db.tx.Exec("SAVEPOINT bulk")
+		if err != nil {
+			return err
+		}
+		commit, err := fn(db.tx)
+		if err != nil || !commit {
+			_, errRollback := db.tx.Exec("ROLLBACK TO SAVEPOINT bulk")
+			if err == nil {
+				err = errRollback
+			}
+			return err
+		}
+		_, err = db.tx.Exec("RELEASE SAVEPOINT bulk")
+		return err
+	}
+
+	tx, err := db.db.Begin()
+	if err != nil {
+		return handlePgError(err)
+	}
+	commit, err := fn(tx)
+	if err != nil || !commit {
+		errRollback := tx.Rollback()
+		if err == nil {
+			err = errRollback
+		}
+		return err
 	}
-	return &DatabasePostgreSQL{session: db}
+	return handlePgError(tx.Commit())
 }
@@ -0,0 +1,112 @@
+package postgresql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/denouche/go-api-skeleton/storage/dao"
+	"github.com/denouche/go-api-skeleton/storage/model"
+)
+
+func (db *DatabasePostgreSQL) CreateIdempotencyRecord(record *model.IdempotencyRecord) error {
+	// an expired record with the same key is replaced, otherwise no row is returned
+	q := `
+		INSERT INTO schema.idempotency_key AS i
+			(key, fingerprint, completed, created_at, expires_at)
+		VALUES
+			($1, $2, false, $3, $4)
+		ON CONFLICT (key) DO UPDATE
+		SET
+			fingerprint = EXCLUDED.fingerprint,
+			completed = false,
+			response_status = NULL,
+			response_headers = NULL,
+			response_body = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE i.expires_at <= now()
+		RETURNING key
+	`
+
+	var key string
+	err := db.session.
+		QueryRow(q, record.Key, record.Fingerprint, record.CreatedAt, record.ExpiresAt).
+		Scan(&key)
+	if err != nil && err != sql.ErrNoRows {
+		return handlePgError(err)
+	}
+	if err == sql.ErrNoRows {
+		return dao.NewDAOError(dao.ErrTypeDuplicate, errors.New("idempotency record already exists"))
+	}
+	return err
+}
+
+func (db *DatabasePostgreSQL) GetIdempotencyRecord(key string) (*model.IdempotencyRecord, error) {
+	q := `
+		SELECT i.key, i.fingerprint, i.completed, i.response_status, i.response_headers, i.response_body, i.created_at, i.expires_at
+		FROM schema.idempotency_key i
+		WHERE i.key = $1
+		AND i.expires_at > now()
+	`
+	row := db.session.QueryRow(q, key)
+
+	r := model.IdempotencyRecord{}
+	var status sql.NullInt64
+	var headers []byte
+	err := row.Scan(&r.Key, &r.Fingerprint, &r.Completed, &status, &headers, &r.ResponseBody, &r.CreatedAt, &r.ExpiresAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, handlePgError(err)
+	}
+	if err == sql.ErrNoRows {
+		return nil, dao.NewDAOError(dao.ErrTypeNotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.ResponseStatus = int(status.Int64)
+	if len(headers) > 0 {
+		err = json.Unmarshal(headers, &r.ResponseHeaders)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &r, nil
+}
+
+func (db *DatabasePostgreSQL) UpdateIdempotencyRecord(record *model.IdempotencyRecord) error {
+	q := `
+		UPDATE schema.idempotency_key
+		SET
+			completed = $2,
+			response_status = $3,
+			response_headers = $4,
+			response_body = $5
+		WHERE key = $1
+	`
+
+	headers, err := json.Marshal(record.ResponseHeaders)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.session.Exec(q, record.Key, record.Completed, record.ResponseStatus, headers, record.ResponseBody)
+	if err != nil && err != sql.ErrNoRows {
+		return handlePgError(err)
+	}
+	return err
+}
+
+func (db *DatabasePostgreSQL) DeleteIdempotencyRecord(key string) error {
+	q := `
+		DELETE FROM schema.idempotency_key
+		WHERE key = $1
+	`
+
+	_, err := db.session.Exec(q, key)
+	if err != nil && err != sql.ErrNoRows {
+		return handlePgError(err)
+	}
+	return err
+}
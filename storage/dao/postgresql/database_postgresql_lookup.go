@@ -0,0 +1,44 @@
+package postgresql
+
+import (
+	"fmt"
+)
+
+// lookupTable describes the table of an entity, and the column of each field which can be looked up
+type lookupTable struct {
+	entity  string
+	table   string
+	columns map[string]string
+}
+
+var lookupTables = map[string]lookupTable{
+	lookupTableTemplate.entity: lookupTableTemplate, // Template lookup
+}
+
+func (db *DatabasePostgreSQL) Exists(entity, field string, value interface{}, excludedID string) (bool, error) {
+	t, ok := lookupTables[entity]
+	if !ok {
+		return false, fmt.Errorf("unknown entity %s", entity)
+	}
+	// the table and column names come from lookupTables only, never from the caller
+	column, ok := t.columns[field]
+	if !ok {
+		return false, fmt.Errorf("field %s of entity %s cannot be looked up", field, entity)
+	}
+
+	q := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM %s u
+			WHERE u.%s = $1
+			AND ($2 = '' OR u.id::text <> $2)
+		)
+	`, t.table, column)
+
+	var exists bool
+	err := db.session.QueryRow(q, value, excludedID).Scan(&exists)
+	if err != nil {
+		return false, handlePgError(err)
+	}
+	return exists, nil
+}
@@ -2,12 +2,24 @@ package postgresql
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/denouche/go-api-skeleton/storage/dao"
 	"github.com/denouche/go-api-skeleton/storage/model"
 	"github.com/lib/pq"
 )
 
+var lookupTableTemplate = lookupTable{
+	entity: "template",
+	table:  "schema.template",
+	columns: map[string]string{
+		"id":   "id",
+		"name": "code",
+	},
+}
+
 func (db *DatabasePostgreSQL) GetAllTemplates() ([]*model.Template, error) {
 	q := `
 		SELECT u.id, u.code, u.created_at, u.updated_at
@@ -41,8 +53,8 @@ func (db *DatabasePostgreSQL) GetTemplateByID(id string) (*model.Template, error
 
 	u := model.Template{}
 	err := row.Scan(&u.ID, &u.Name, &u.CreatedAt, &u.UpdatedAt)
-	if errPq, ok := err.(*pq.Error); ok {
-		return nil, handlePgError(errPq)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, handlePgError(err)
 	}
 	if err == sql.ErrNoRows {
 		return nil, dao.NewDAOError(dao.ErrTypeNotFound, err)
@@ -62,8 +74,8 @@ func (db *DatabasePostgreSQL) CreateTemplate(template *model.Template) error {
 	err := db.session.
 		QueryRow(q, template.Name).
 		Scan(&template.ID, &template.CreatedAt)
-	if errPq, ok := err.(*pq.Error); ok {
-		return handlePgError(errPq)
+	if err != nil && err != sql.ErrNoRows {
+		return handlePgError(err)
 	}
 	return err
 }
@@ -75,8 +87,8 @@ func (db *DatabasePostgreSQL) DeleteTemplate(id string) error {
 	`
 
 	_, err := db.session.Exec(q, id)
-	if errPq, ok := err.(*pq.Error); ok {
-		return handlePgError(errPq)
+	if err != nil && err != sql.ErrNoRows {
+		return handlePgError(err)
 	}
 	return err
 }
@@ -93,8 +105,213 @@ func (db *DatabasePostgreSQL) UpdateTemplate(template *model.Template) error {
 	err := db.session.
 		QueryRow(q, template.ID, template.Name).
 		Scan(&template.UpdatedAt)
-	if errPq, ok := err.(*pq.Error); ok {
-		return handlePgError(errPq)
+	if err != nil && err != sql.ErrNoRows {
+		return handlePgError(err)
 	}
 	return err
 }
+
+// createTemplatesChunkSize is the number of templates inserted by each INSERT statement,
+// each template being a bind parameter and PostgreSQL accepting at most 65535 of them.
+const createTemplatesChunkSize = 1000
+
+// CreateTemplates inserts the templates with multi-row INSERT statements of createTemplatesChunkSize rows, in a
+// transaction or a savepoint. When atomic is false, the templates in conflict with existing ones are skipped and
+// reported as duplicated.
+func (db *DatabasePostgreSQL) CreateTemplates(templates []*model.Template, atomic bool) ([]error, error) {
+	errs := make([]error, len(templates))
+	if len(templates) == 0 {
+		return errs, nil
+	}
+
+	err := db.runInTransaction(func(tx querier) (bool, error) {
+		for start := 0; start < len(templates); start += createTemplatesChunkSize {
+			end := start + createTemplatesChunkSize
+			if end > len(templates) {
+				end = len(templates)
+			}
+			if err := createTemplatesChunk(tx, templates[start:end], errs[start:end], atomic); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// createTemplatesChunk inserts the templates with a single multi-row INSERT, and sets the errors of the ones skipped
+func createTemplatesChunk(tx querier, templates []*model.Template, errs []error, atomic bool) error {
+	values := make([]string, 0, len(templates))
+	args := make([]interface{}, 0, len(templates))
+	for i, template := range templates {
+		values = append(values, fmt.Sprintf("($%d)", i+1))
+		args = append(args, template.Name)
+	}
+	onConflict := ""
+	if !atomic {
+		onConflict = "ON CONFLICT DO NOTHING"
+	}
+	q := fmt.Sprintf(`
+		INSERT INTO schema.template
+			(code)
+		VALUES
+			%s
+		%s
+		RETURNING id, code, created_at
+	`, strings.Join(values, ", "), onConflict)
+
+	rows, err := tx.Query(q, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return handlePgError(err)
+	}
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// the inserted templates are matched by their unique code, the missing ones were in conflict
+	created := make(map[string]*model.Template, len(templates))
+	for rows.Next() {
+		u := model.Template{}
+		err := rows.Scan(&u.ID, &u.Name, &u.CreatedAt)
+		if err != nil {
+			return err
+		}
+		created[u.Name] = &u
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i, template := range templates {
+		u, ok := created[template.Name]
+		if !ok {
+			errs[i] = dao.NewDAOError(dao.ErrTypeDuplicate, errors.New("template already exists"))
+			continue
+		}
+		// a code given twice in the batch is inserted once
+		delete(created, template.Name)
+		template.ID = u.ID
+		template.CreatedAt = u.CreatedAt
+	}
+	return nil
+}
+
+// UpdateTemplates updates all the templates with a single UPDATE statement, in a transaction or a savepoint.
+// When atomic is true, the transaction is rolled back if any template is not found.
+func (db *DatabasePostgreSQL) UpdateTemplates(templates []*model.Template, atomic bool) ([]error, error) {
+	errs := make([]error, len(templates))
+	if len(templates) == 0 {
+		return errs, nil
+	}
+
+	ids := make([]string, 0, len(templates))
+	codes := make([]string, 0, len(templates))
+	for _, template := range templates {
+		ids = append(ids, template.ID)
+		codes = append(codes, template.Name)
+	}
+	q := `
+		UPDATE schema.template u
+		SET
+			code = v.code
+		FROM (SELECT unnest($1::text[]) AS id, unnest($2::text[]) AS code) v
+		WHERE u.id::text = v.id
+		RETURNING u.id, u.code, u.created_at, u.updated_at
+	`
+
+	err := db.runInTransaction(func(tx querier) (bool, error) {
+		rows, err := tx.Query(q, pq.Array(ids), pq.Array(codes))
+		if err != nil && err != sql.ErrNoRows {
+			return false, handlePgError(err)
+		}
+		if err != nil {
+			return false, err
+		}
+		defer rows.Close()
+
+		updated := make(map[string]*model.Template, len(templates))
+		for rows.Next() {
+			u := model.Template{}
+			err := rows.Scan(&u.ID, &u.Name, &u.CreatedAt, &u.UpdatedAt)
+			if err != nil {
+				return false, err
+			}
+			updated[u.ID] = &u
+		}
+		if err := rows.Err(); err != nil {
+			return false, err
+		}
+
+		failed := false
+		for i, template := range templates {
+			u, ok := updated[template.ID]
+			if !ok {
+				errs[i] = dao.NewDAOError(dao.ErrTypeNotFound, sql.ErrNoRows)
+				failed = true
+				continue
+			}
+			*template = *u
+		}
+		return !atomic || !failed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// DeleteTemplates deletes all the templates with a single DELETE statement, in a transaction or a savepoint.
+// When atomic is true, the transaction is rolled back if any template is not found.
+func (db *DatabasePostgreSQL) DeleteTemplates(ids []string, atomic bool) ([]error, error) {
+	errs := make([]error, len(ids))
+	if len(ids) == 0 {
+		return errs, nil
+	}
+
+	q := `
+		DELETE FROM schema.template
+		WHERE id::text = ANY($1)
+		RETURNING id
+	`
+
+	err := db.runInTransaction(func(tx querier) (bool, error) {
+		rows, err := tx.Query(q, pq.Array(ids))
+		if err != nil && err != sql.ErrNoRows {
+			return false, handlePgError(err)
+		}
+		if err != nil {
+			return false, err
+		}
+		defer rows.Close()
+
+		deleted := make(map[string]bool, len(ids))
+		for rows.Next() {
+			var id string
+			err := rows.Scan(&id)
+			if err != nil {
+				return false, err
+			}
+			deleted[id] = true
+		}
+		if err := rows.Err(); err != nil {
+			return false, err
+		}
+
+		failed := false
+		for i, id := range ids {
+			if !deleted[id] {
+				errs[i] = dao.NewDAOError(dao.ErrTypeNotFound, sql.ErrNoRows)
+				failed = true
+			}
+		}
+		return !atomic || !failed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/denouche/go-api-skeleton/storage/dao"
+	"github.com/denouche/go-api-skeleton/utils/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DatabaseTracing records a span for each call to the database it wraps, child of the span of its context
+type DatabaseTracing struct {
+	db  dao.Database
+	ctx context.Context
+}
+
+// NewDatabaseTracing returns db tracing its calls in ctx, usually the context of the request
+func NewDatabaseTracing(ctx context.Context, db dao.Database) dao.Database {
+	if t, ok := db.(*DatabaseTracing); ok {
+		// not to record the calls twice
+		db = t.db
+	}
+	return &DatabaseTracing{db: db, ctx: ctx}
+}
+
+// startSpan starts the span of the call to operation, which must be ended with endSpan
+func (db *DatabaseTracing) startSpan(operation string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(db.ctx, "dao "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBOperationName(operation)),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// endBulkSpan ends the span of a bulk call, failed when the call fails, or when any item fails
+func endBulkSpan(span trace.Span, errs []error, err error) {
+	for _, e := range errs {
+		if e != nil && err == nil {
+			span.SetStatus(codes.Error, "some items failed")
+			break
+		}
+	}
+	endSpan(span, err)
+}
+
+func (db *DatabaseTracing) CheckHealth(ctx context.Context) error {
+	return db.db.CheckHealth(ctx)
+}
+
+func (db *DatabaseTracing) WithTransaction(fn func(tx dao.Database) error) (err error) {
+	ctx, span := db.startSpan("WithTransaction")
+	defer func() { endSpan(span, err) }()
+	return db.db.WithTransaction(func(tx dao.Database) error {
+		// the calls made in the transaction are children of its span
+		return fn(&DatabaseTracing{db: tx, ctx: ctx})
+	})
+}
+
+func (db *DatabaseTracing) Exists(entity, field string, value interface{}, excludedID string) (exists bool, err error) {
+	_, span := db.startSpan("Exists")
+	defer func() { endSpan(span, err) }()
+	return db.db.Exists(entity, field, value, excludedID)
+}
@@ -0,0 +1,29 @@
+package tracing
+
+import (
+	"github.com/denouche/go-api-skeleton/storage/model"
+)
+
+func (db *DatabaseTracing) CreateIdempotencyRecord(record *model.IdempotencyRecord) (err error) {
+	_, span := db.startSpan("CreateIdempotencyRecord")
+	defer func() { endSpan(span, err) }()
+	return db.db.CreateIdempotencyRecord(record)
+}
+
+func (db *DatabaseTracing) GetIdempotencyRecord(key string) (record *model.IdempotencyRecord, err error) {
+	_, span := db.startSpan("GetIdempotencyRecord")
+	defer func() { endSpan(span, err) }()
+	return db.db.GetIdempotencyRecord(key)
+}
+
+func (db *DatabaseTracing) UpdateIdempotencyRecord(record *model.IdempotencyRecord) (err error) {
+	_, span := db.startSpan("UpdateIdempotencyRecord")
+	defer func() { endSpan(span, err) }()
+	return db.db.UpdateIdempotencyRecord(record)
+}
+
+func (db *DatabaseTracing) DeleteIdempotencyRecord(key string) (err error) {
+	_, span := db.startSpan("DeleteIdempotencyRecord")
+	defer func() { endSpan(span, err) }()
+	return db.db.DeleteIdempotencyRecord(key)
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"github.com/denouche/go-api-skeleton/storage/model"
+)
+
+func (db *DatabaseTracing) GetAllTemplates() (templates []*model.Template, err error) {
+	_, span := db.startSpan("GetAllTemplates")
+	defer func() { endSpan(span, err) }()
+	return db.db.GetAllTemplates()
+}
+
+func (db *DatabaseTracing) GetTemplateByID(templateID string) (template *model.Template, err error) {
+	_, span := db.startSpan("GetTemplateByID")
+	defer func() { endSpan(span, err) }()
+	return db.db.GetTemplateByID(templateID)
+}
+
+func (db *DatabaseTracing) CreateTemplate(template *model.Template) (err error) {
+	_, span := db.startSpan("CreateTemplate")
+	defer func() { endSpan(span, err) }()
+	return db.db.CreateTemplate(template)
+}
+
+func (db *DatabaseTracing) DeleteTemplate(templateID string) (err error) {
+	_, span := db.startSpan("DeleteTemplate")
+	defer func() { endSpan(span, err) }()
+	return db.db.DeleteTemplate(templateID)
+}
+
+func (db *DatabaseTracing) UpdateTemplate(template *model.Template) (err error) {
+	_, span := db.startSpan("UpdateTemplate")
+	defer func() { endSpan(span, err) }()
+	return db.db.UpdateTemplate(template)
+}
+
+func (db *DatabaseTracing) CreateTemplates(templates []*model.Template, atomic bool) (errs []error, err error) {
+	_, span := db.startSpan("CreateTemplates")
+	defer func() { endBulkSpan(span, errs, err) }()
+	return db.db.CreateTemplates(templates, atomic)
+}
+
+func (db *DatabaseTracing) UpdateTemplates(templates []*model.Template, atomic bool) (errs []error, err error) {
+	_, span := db.startSpan("UpdateTemplates")
+	defer func() { endBulkSpan(span, errs, err) }()
+	return db.db.UpdateTemplates(templates, atomic)
+}
+
+func (db *DatabaseTracing) DeleteTemplates(ids []string, atomic bool) (errs []error, err error) {
+	_, span := db.startSpan("DeleteTemplates")
+	defer func() { endBulkSpan(span, errs, err) }()
+	return db.db.DeleteTemplates(ids, atomic)
+}
@@ -0,0 +1,29 @@
+package model
+
+import "encoding/json"
+
+const (
+	AdmissionOperationCreate = "CREATE"
+	AdmissionOperationUpdate = "UPDATE"
+	AdmissionOperationDelete = "DELETE"
+)
+
+// AdmissionRequest is sent to the admission webhooks before an entity is written.
+// Object is the proposed data, absent on deletion. OldObject is the current entity, absent on creation.
+// @openapi:schema
+type AdmissionRequest struct {
+	UID       string          `json:"uid"`
+	Entity    string          `json:"entity"`
+	Operation string          `json:"operation"`
+	Object    json.RawMessage `json:"object,omitempty"`
+	OldObject json.RawMessage `json:"oldObject,omitempty"`
+}
+
+// AdmissionResponse is the answer of an admission webhook. Patch is a JSON Patch (RFC 6902) to apply to the object,
+// ignored on deletion.
+// @openapi:schema
+type AdmissionResponse struct {
+	Allowed bool             `json:"allowed"`
+	Message string           `json:"message,omitempty"`
+	Patch   []PatchOperation `json:"patch,omitempty"`
+}
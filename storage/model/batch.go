@@ -0,0 +1,61 @@
+package model
+
+import "encoding/json"
+
+const (
+	// BatchModeAllOrNothing makes a batch fail entirely when any of its items fails
+	BatchModeAllOrNothing = "all_or_nothing"
+	// BatchModeBestEffort makes each item of a batch processed independently
+	BatchModeBestEffort = "best_effort"
+)
+
+// @openapi:schema
+type BatchItemResult struct {
+	Index  int         `json:"index"`
+	Status int         `json:"status"`
+	Data   interface{} `json:"data,omitempty"`
+	Error  *APIError   `json:"error,omitempty"`
+}
+
+// @openapi:schema
+type BatchResponse struct {
+	Results []*BatchItemResult `json:"results"`
+}
+
+// @openapi:schema
+type BatchDeleteItem struct {
+	ID      string `json:"id" validate:"required"`
+	IfMatch string `json:"ifMatch"`
+}
+
+// @openapi:schema
+type BatchDeleteRequest struct {
+	Mode  string            `json:"mode" validate:"omitempty,oneof=all_or_nothing best_effort"`
+	Items []BatchDeleteItem `json:"items" validate:"required,min=1"`
+}
+
+// @openapi:schema
+type BatchOperation struct {
+	Method  string            `json:"method" validate:"required,oneof=GET HEAD POST PUT PATCH DELETE"`
+	Path    string            `json:"path" validate:"required,startswith=/"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// @openapi:schema
+type BatchOperationsRequest struct {
+	Transactional bool             `json:"transactional"`
+	Operations    []BatchOperation `json:"operations" validate:"required,min=1,dive"`
+}
+
+// @openapi:schema
+type BatchOperationResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// @openapi:schema
+type BatchOperationsResponse struct {
+	Responses []*BatchOperationResponse `json:"responses"`
+}
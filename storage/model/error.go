@@ -18,12 +18,26 @@ var (
 		Description: "the data are not valid",
 	}
 
+	// 403
+	ErrAdmissionDenied = APIError{
+		Type:        "admission_denied",
+		HTTPCode:    http.StatusForbidden,
+		Description: "the request has been denied by an admission webhook",
+	}
+
 	// 404
 	ErrNotFound = APIError{
 		Type:     "not_found",
 		HTTPCode: http.StatusNotFound,
 	}
 
+	// 405
+	ErrMethodNotAllowed = APIError{
+		Type:        "method_not_allowed",
+		HTTPCode:    http.StatusMethodNotAllowed,
+		Description: "the method is not allowed for the requested resource",
+	}
+
 	// 40x
 	ErrAlreadyExists = APIError{
 		Type:     "already_exists",
@@ -34,12 +48,83 @@ var (
 		HTTPCode:    http.StatusPreconditionFailed,
 		Description: "Model version mismatched",
 	}
+	ErrIdempotencyRequestInProgress = APIError{
+		Type:        "idempotency_request_in_progress",
+		HTTPCode:    http.StatusConflict,
+		Description: "a request with the same idempotency key is being processed, please retry later",
+		Headers:     map[string][]string{"Retry-After": {"1"}},
+	}
+	ErrPatchFailed = APIError{
+		Type:        "patch_failed",
+		HTTPCode:    http.StatusConflict,
+		Description: "the patch cannot be applied to the resource",
+	}
+	ErrUnsupportedMediaType = APIError{
+		Type:        "unsupported_media_type",
+		HTTPCode:    http.StatusUnsupportedMediaType,
+		Description: "the request content type is not supported",
+	}
+	ErrConflict = APIError{
+		Type:        "conflict",
+		HTTPCode:    http.StatusConflict,
+		Description: "the resource has been modified concurrently, please retry",
+	}
+	ErrReferenceNotFound = APIError{
+		Type:        "reference_not_found",
+		HTTPCode:    http.StatusUnprocessableEntity,
+		Description: "the data reference an entity which does not exist",
+	}
+	ErrIdempotencyKeyReused = APIError{
+		Type:        "idempotency_key_reused",
+		HTTPCode:    http.StatusUnprocessableEntity,
+		Description: "the idempotency key has already been used with another request",
+	}
+	ErrPreconditionRequired = APIError{
+		Type:        "precondition_required",
+		HTTPCode:    http.StatusPreconditionRequired,
+		Description: "This request must be conditional, please give the model version in the If-Match header, or its date in the If-Unmodified-Since header",
+	}
+
+	// 413
+	ErrRequestTooLarge = APIError{
+		Type:        "request_too_large",
+		HTTPCode:    http.StatusRequestEntityTooLarge,
+		Description: "the request is too large",
+	}
+
+	// 424
+	ErrBatchAborted = APIError{
+		Type:        "batch_aborted",
+		HTTPCode:    http.StatusFailedDependency,
+		Description: "this item has not been processed because another item of the batch failed",
+	}
 
 	// 50x
 	ErrInternalServer = APIError{
 		Type:     "internal_server_error",
 		HTTPCode: http.StatusInternalServerError,
 	}
+	ErrResponseValidation = APIError{
+		Type:        "response_validation",
+		HTTPCode:    http.StatusInternalServerError,
+		Description: "the response does not match the openapi document",
+	}
+	ErrAdmissionWebhookFailed = APIError{
+		Type:        "admission_webhook_failed",
+		HTTPCode:    http.StatusBadGateway,
+		Description: "an admission webhook cannot be called or answered badly",
+	}
+	ErrServiceUnavailable = APIError{
+		Type:        "service_unavailable",
+		HTTPCode:    http.StatusServiceUnavailable,
+		Description: "the service is temporarily unavailable, please retry later",
+		Headers:     map[string][]string{"Retry-After": {"5"}},
+	}
+	ErrGatewayTimeout = APIError{
+		Type:        "timeout",
+		HTTPCode:    http.StatusGatewayTimeout,
+		Description: "the database did not answer in time",
+	}
 )
 
 // @openapi:schema
@@ -49,6 +134,10 @@ type APIError struct {
 	Description string              `json:"error_description"`
 	Details     []FieldError        `json:"error_details,omitempty"`
 	Headers     map[string][]string `json:"-"`
+	// MessageKey is the key of the localized description in the message catalog, formatted with MessageArgs.
+	// When empty, the description is localized from the error type if it is the default one.
+	MessageKey  string        `json:"-"`
+	MessageArgs []interface{} `json:"-"`
 }
 
 // @openapi:schema
@@ -56,6 +145,8 @@ type FieldError struct {
 	Field       string `json:"field"`
 	Constraint  string `json:"constraint"`
 	Description string `json:"description"`
+	// Param is the param of the constraint, used to localize the description
+	Param string `json:"-"`
 }
 
 func (e *APIError) Error() string {
@@ -0,0 +1,24 @@
+package model
+
+const (
+	HealthStatusUp       = "up"
+	HealthStatusDown     = "down"
+	HealthStatusStarting = "starting"
+	HealthStatusStopping = "stopping"
+)
+
+// HealthReport is the status of the api, down when any of its checks is down,
+// starting or stopping when the api is not ready to serve requests
+// @openapi:schema
+type HealthReport struct {
+	Status string                        `json:"status" validate:"oneof=up down starting stopping"`
+	Checks map[string]*HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckResult is the result of a check of a dependency of the api, with its latency in milliseconds
+// @openapi:schema
+type HealthCheckResult struct {
+	Status    string  `json:"status" validate:"oneof=up down"`
+	LatencyMS float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// IdempotencyRecord stores the response sent to a request made with an Idempotency-Key header,
+// in order to replay it when the client retries the same request
+type IdempotencyRecord struct {
+	Key             string              `json:"key" bson:"_id"`
+	Fingerprint     string              `json:"fingerprint" bson:"fingerprint"`
+	Completed       bool                `json:"completed" bson:"completed"`
+	ResponseStatus  int                 `json:"responseStatus" bson:"responseStatus"`
+	ResponseHeaders map[string][]string `json:"responseHeaders" bson:"responseHeaders"`
+	ResponseBody    []byte              `json:"responseBody" bson:"responseBody"`
+	CreatedAt       time.Time           `json:"createdAt" bson:"createdAt"`
+	ExpiresAt       time.Time           `json:"expiresAt" bson:"expiresAt"`
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var regexpFieldIndex = regexp.MustCompile(`\[(\w+)\]`)
+
+// ProblemDetails is the RFC 7807 representation of an APIError
+// @openapi:schema
+type ProblemDetails struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Errors   []ProblemError `json:"errors,omitempty"`
+}
+
+// ProblemError describes a validation error of a ProblemDetails, the invalid field is given as a JSON Pointer (RFC 6901)
+// @openapi:schema
+type ProblemError struct {
+	Pointer    string `json:"pointer"`
+	Constraint string `json:"constraint"`
+	Detail     string `json:"detail"`
+}
+
+// ToProblemDetails converts the APIError to its RFC 7807 representation.
+// The problem type URI is the APIError type appended to typeBaseURI.
+func (e *APIError) ToProblemDetails(typeBaseURI, instance string) ProblemDetails {
+	p := ProblemDetails{
+		Type:     typeBaseURI + e.Type,
+		Title:    http.StatusText(e.HTTPCode),
+		Status:   e.HTTPCode,
+		Detail:   e.Description,
+		Instance: instance,
+	}
+	for _, d := range e.Details {
+		p.Errors = append(p.Errors, ProblemError{
+			Pointer:    FieldToJSONPointer(d.Field),
+			Constraint: d.Constraint,
+			Detail:     d.Description,
+		})
+	}
+	return p
+}
+
+// FieldToJSONPointer converts a field path as given in FieldError ($.items[0].name) to a JSON Pointer (/items/0/name)
+func FieldToJSONPointer(field string) string {
+	field = strings.TrimPrefix(strings.TrimPrefix(field, "$"), ".")
+	if field == "" {
+		return ""
+	}
+	field = regexpFieldIndex.ReplaceAllString(field, ".$1")
+
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	segments := strings.Split(field, ".")
+	for i, s := range segments {
+		segments[i] = escaper.Replace(s)
+	}
+	return "/" + strings.Join(segments, "/")
+}
@@ -1,12 +1,11 @@
 package validators
 
 import (
-	"fmt"
 	"regexp"
-	"strings"
 
 	"github.com/denouche/go-api-skeleton/storage/model"
 	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
 	"gopkg.in/go-playground/validator.v9"
 )
 
@@ -16,19 +15,21 @@ func NewDataValidationAPIError(err error) model.APIError {
 	apiErr := model.ErrDataValidation
 	if err != nil {
 		if _, ok := err.(*validator.InvalidValidationError); ok {
-			utils.GetLogger().WithError(err).WithField("templateAPIErr", apiErr).Error("InvalidValidationError")
+			utils.GetComponentLogger(utils.LoggerComponentValidators).WithError(err).WithField("templateAPIErr", apiErr).Error("InvalidValidationError")
 		} else {
 			for _, e := range err.(validator.ValidationErrors) {
 				reason := e.Tag()
 				if _, ok := CustomValidators[e.Tag()]; ok {
-					reason = truncatingSprintf(CustomValidators[e.Tag()].Message, e.Param())
+					reason = i18n.TruncatingSprintf(CustomValidators[e.Tag()].Message, e.Param())
 				}
+				reason = i18n.Sprintf(i18n.DefaultLanguage(), i18n.KeyPrefixValidation+e.Tag(), reason, e.Param())
 
 				namespaceWithoutStructName := regexpValidatorNamespacePrefix.ReplaceAllString(e.Namespace(), "$.")
 				fe := model.FieldError{
 					Field:       namespaceWithoutStructName,
 					Constraint:  e.Tag(),
 					Description: reason,
+					Param:       e.Param(),
 				}
 				apiErr.Details = append(apiErr.Details, fe)
 			}
@@ -36,9 +37,3 @@ func NewDataValidationAPIError(err error) model.APIError {
 	}
 	return apiErr
 }
-
-// truncatingSprintf is used as fmt.Sprintf but allow to truncate the additional parameters given when there is more parameters than %v in str
-func truncatingSprintf(str string, args ...interface{}) string {
-	n := strings.Count(str, "%v")
-	return fmt.Sprintf(str, args[:n]...)
-}
@@ -0,0 +1,142 @@
+package validators
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+const (
+	// ruleVariableName is the name of the validated entity in the rules expressions
+	ruleVariableName = "self"
+	// ruleConstraintPrefix prefixes the rule name in the constraint of the field errors
+	ruleConstraintPrefix = "rule:"
+
+	defaultRuleMessage = "This data does not respect the rule %v"
+)
+
+// Rule is a business rule on an entity, written as a CEL expression evaluated to a boolean,
+// e.g. `self.name.size() <= 64 && !self.name.startsWith("tmp")`. The entity fields are named as in json.
+type Rule struct {
+	Name       string `json:"name" mapstructure:"name"`
+	Expression string `json:"expression" mapstructure:"expression"`
+	// Field is the field reported in the field error when the rule fails, the whole entity when empty
+	Field string `json:"field" mapstructure:"field"`
+	// Message is the description of the field error when the rule fails
+	Message string `json:"message" mapstructure:"message"`
+}
+
+type compiledRule struct {
+	Rule
+	program cel.Program
+}
+
+var (
+	rulesLock sync.RWMutex
+	rules     = map[string][]compiledRule{}
+)
+
+// SetRules compiles the rules by entity, and replaces the current ones if they are all valid.
+// On error, the current rules are kept.
+func SetRules(rulesByEntity map[string][]Rule) error {
+	env, err := cel.NewEnv(cel.Variable(ruleVariableName, cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		return err
+	}
+
+	compiled := make(map[string][]compiledRule, len(rulesByEntity))
+	for entity, entityRules := range rulesByEntity {
+		for i, rule := range entityRules {
+			if rule.Name == "" {
+				rule.Name = fmt.Sprintf("%s-%d", entity, i)
+			}
+			ast, issues := env.Compile(rule.Expression)
+			if issues != nil && issues.Err() != nil {
+				return fmt.Errorf("invalid expression of rule %s: %w", rule.Name, issues.Err())
+			}
+			if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+				return fmt.Errorf("invalid expression of rule %s: it must be evaluated to a boolean, not %s", rule.Name, ast.OutputType())
+			}
+			program, err := env.Program(ast)
+			if err != nil {
+				return fmt.Errorf("invalid expression of rule %s: %w", rule.Name, err)
+			}
+			compiled[entity] = append(compiled[entity], compiledRule{Rule: rule, program: program})
+		}
+	}
+
+	rulesLock.Lock()
+	rules = compiled
+	rulesLock.Unlock()
+	return nil
+}
+
+// ValidateRules evaluates the rules of entity against data, which must have already been validated with its struct tags.
+// It returns a data validation error with a field error for each failed rule, or nil.
+func ValidateRules(entity string, data interface{}) *model.APIError {
+	rulesLock.RLock()
+	entityRules := rules[entity]
+	rulesLock.RUnlock()
+	if len(entityRules) == 0 {
+		return nil
+	}
+
+	self, err := toRuleVariable(data)
+	if err != nil {
+		utils.GetComponentLogger(utils.LoggerComponentValidators).WithError(err).WithField("entity", entity).Error("error while converting data to evaluate rules")
+		apiErr := model.ErrInternalServer
+		return &apiErr
+	}
+
+	var details []model.FieldError
+	for _, rule := range entityRules {
+		out, _, err := rule.program.Eval(map[string]interface{}{ruleVariableName: self})
+		if err == nil && out == types.True {
+			continue
+		}
+		if err != nil {
+			// a rule which cannot be evaluated (missing field, bad type) is considered as failed
+			utils.GetComponentLogger(utils.LoggerComponentValidators).WithError(err).WithField("entity", entity).WithField("rule", rule.Name).Warn("error while evaluating rule")
+		}
+		details = append(details, newRuleFieldError(rule.Rule))
+	}
+	if len(details) == 0 {
+		return nil
+	}
+
+	apiErr := model.ErrDataValidation
+	apiErr.Details = details
+	return &apiErr
+}
+
+func newRuleFieldError(rule Rule) model.FieldError {
+	field := "$"
+	if rule.Field != "" {
+		field += "." + rule.Field
+	}
+	message := rule.Message
+	if message == "" {
+		message = fmt.Sprintf(defaultRuleMessage, rule.Name)
+	}
+	return model.FieldError{
+		Field:       field,
+		Constraint:  ruleConstraintPrefix + rule.Name,
+		Description: message,
+	}
+}
+
+// toRuleVariable converts data to the map given to the expressions, with the fields named as in json
+func toRuleVariable(data interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	err = json.Unmarshal(b, &result)
+	return result, err
+}
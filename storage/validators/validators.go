@@ -2,6 +2,7 @@ package validators
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"regexp"
 	"strings"
@@ -21,11 +22,26 @@ var (
 		"required": {
 			Message: "This field is required and cannot be empty",
 		},
+		"unique": {
+			Message:   "This field should be unique",
+			Validator: newDBValidator(validateUnique),
+		},
+		"exists": {
+			Message:   "This field should reference an existing %v",
+			Validator: newDBValidator(validateExists),
+		},
 	}
 )
 
-type validationContext struct {
-	DB dao.Database
+// ValidationContext holds the data the validators may need, besides the validated value.
+// Entity and ID describe the entity being validated, ID is empty on creation.
+type ValidationContext struct {
+	DB     dao.Database
+	Entity string
+	ID     string
+
+	// err is the first database error met by a validator
+	err error
 }
 
 type customValidator struct {
@@ -33,10 +49,102 @@ type customValidator struct {
 	Validator validator.FuncCtx
 }
 
+// DBValidatorFunc is a validator using the database. The returned error is a database error,
+// the validation is then stopped and the error is returned by GetValidationContextError.
+type DBValidatorFunc func(ctx context.Context, vc *ValidationContext, fl validator.FieldLevel) (bool, error)
+
+// RegisterValidator adds a validator for tag to the validators created afterwards by NewValidator.
+// message is the description of the field errors, formatted with the tag param. It can be translated
+// in the messages files with the key validation.<tag>.
+func RegisterValidator(tag, message string, fn validator.FuncCtx) {
+	CustomValidators[tag] = customValidator{
+		Message:   message,
+		Validator: fn,
+	}
+}
+
+// RegisterDBValidator adds a validator for tag using the database of the validation context, see RegisterValidator
+func RegisterDBValidator(tag, message string, fn DBValidatorFunc) {
+	RegisterValidator(tag, message, newDBValidator(fn))
+}
+
+func newDBValidator(fn DBValidatorFunc) validator.FuncCtx {
+	return func(ctx context.Context, fl validator.FieldLevel) bool {
+		vc := GetValidationContext(ctx)
+		if vc == nil || vc.DB == nil {
+			// the database is not known, nothing can be checked
+			return true
+		}
+		if vc.err != nil {
+			return true
+		}
+		ok, err := fn(ctx, vc, fl)
+		if err != nil {
+			vc.err = err
+			return true
+		}
+		return ok
+	}
+}
+
 func validateRegexp(ctx context.Context, fl validator.FieldLevel) bool {
 	return regexp.MustCompile(fl.Param()).MatchString(fl.Field().String())
 }
 
+// validateUnique checks, with unique=field, that no other entity of the validated type has the same value for field.
+// Without param, it checks that the values of a slice, array or map are unique.
+func validateUnique(ctx context.Context, vc *ValidationContext, fl validator.FieldLevel) (bool, error) {
+	if fl.Param() == "" {
+		return areValuesUnique(fl.Field()), nil
+	}
+	if vc.Entity == "" || isEmpty(fl.Field()) {
+		return true, nil
+	}
+	exists, err := vc.DB.Exists(vc.Entity, fl.Param(), fl.Field().Interface(), vc.ID)
+	return !exists, err
+}
+
+// validateExists checks, with exists=entity, that the value is the id of an existing entity.
+// The field referenced can be given with exists=entity.field.
+func validateExists(ctx context.Context, vc *ValidationContext, fl validator.FieldLevel) (bool, error) {
+	if isEmpty(fl.Field()) {
+		return true, nil
+	}
+	entity, field := fl.Param(), "id"
+	if i := strings.Index(entity, "."); i >= 0 {
+		entity, field = entity[:i], entity[i+1:]
+	}
+	return vc.DB.Exists(entity, field, fl.Field().Interface(), "")
+}
+
+func areValuesUnique(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.Slice, reflect.Array:
+		seen := make(map[string]bool, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			key := fmt.Sprint(field.Index(i).Interface())
+			if seen[key] {
+				return false
+			}
+			seen[key] = true
+		}
+	case reflect.Map:
+		seen := make(map[string]bool, field.Len())
+		for _, k := range field.MapKeys() {
+			key := fmt.Sprint(field.MapIndex(k).Interface())
+			if seen[key] {
+				return false
+			}
+			seen[key] = true
+		}
+	}
+	return true
+}
+
+func isEmpty(field reflect.Value) bool {
+	return !field.IsValid() || field.IsZero()
+}
+
 func NewValidator() *validator.Validate {
 	va := validator.New()
 
@@ -58,8 +166,30 @@ func NewValidator() *validator.Validate {
 }
 
 func NewContextWithValidationContext(parentCtx context.Context, db dao.Database) context.Context {
-	vc := &validationContext{
-		DB: db,
+	return NewContextWithEntityValidationContext(parentCtx, db, "", "")
+}
+
+// NewContextWithEntityValidationContext returns a context to validate the entity of type entity with the given id,
+// empty on creation. They are used by the validators checking the entity against the other ones.
+func NewContextWithEntityValidationContext(parentCtx context.Context, db dao.Database, entity, id string) context.Context {
+	vc := &ValidationContext{
+		DB:     db,
+		Entity: entity,
+		ID:     id,
 	}
 	return context.WithValue(parentCtx, ContextKeyValidator, vc)
 }
+
+// GetValidationContext returns the validation context of ctx, or nil
+func GetValidationContext(ctx context.Context) *ValidationContext {
+	vc, _ := ctx.Value(ContextKeyValidator).(*ValidationContext)
+	return vc
+}
+
+// GetValidationContextError returns the database error met by a validator during the validations made with ctx
+func GetValidationContextError(ctx context.Context) error {
+	if vc := GetValidationContext(ctx); vc != nil {
+		return vc.err
+	}
+	return nil
+}
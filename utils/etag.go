@@ -4,12 +4,19 @@ import (
 	"crypto/sha1"
 	"encoding/json"
 	"fmt"
+	"strings"
+)
+
+const (
+	etagWeakPrefix = "W/"
+	etagAny        = "*"
 )
 
 func getHash(str string) string {
 	return fmt.Sprintf("%x", sha1.Sum([]byte(str)))
 }
 
+// GenerateEtag returns a strong entity tag computed from the JSON representation of data
 func GenerateEtag(data interface{}) (string, error) {
 	b, err := json.Marshal(data)
 	if err != nil {
@@ -21,6 +28,44 @@ func GenerateEtag(data interface{}) (string, error) {
 	return tag, nil
 }
 
+// IsWeakEtag returns true if the given entity tag is a weak one
+func IsWeakEtag(etag string) bool {
+	return strings.HasPrefix(etag, etagWeakPrefix)
+}
+
+// ParseEtags splits the value of an If-Match or If-None-Match header into entity tags
+func ParseEtags(header string) []string {
+	etags := make([]string, 0)
+	for _, etag := range strings.Split(header, ",") {
+		etag = strings.TrimSpace(etag)
+		if etag != "" {
+			etags = append(etags, etag)
+		}
+	}
+	return etags
+}
+
+// MatchEtag checks if etag is in the list given in an If-Match or If-None-Match header value.
+// The strong comparison, used for If-Match, never matches weak entity tags.
+// The weak comparison, used for If-None-Match, ignores the weakness indicator.
+func MatchEtag(header, etag string, strong bool) bool {
+	for _, candidate := range ParseEtags(header) {
+		if candidate == etagAny {
+			return true
+		}
+		if strong {
+			if !IsWeakEtag(candidate) && !IsWeakEtag(etag) && candidate == etag {
+				return true
+			}
+			continue
+		}
+		if strings.TrimPrefix(candidate, etagWeakPrefix) == strings.TrimPrefix(etag, etagWeakPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func IsSameVersion(expectedEtag string, resource interface{}) bool {
 	etag, err := GenerateEtag(resource)
 	if err != nil {
@@ -31,9 +76,5 @@ func IsSameVersion(expectedEtag string, resource interface{}) bool {
 		return false
 	}
 
-	if expectedEtag != etag {
-		return false
-	}
-
-	return true
+	return MatchEtag(expectedEtag, etag, true)
 }
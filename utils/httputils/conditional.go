@@ -0,0 +1,76 @@
+package httputils
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// LastModifier is implemented by the resources knowing their last modification date
+type LastModifier interface {
+	LastModified() time.Time
+}
+
+// getLastModified returns the last modification date of data, truncated to the second as HTTP dates are
+func getLastModified(data interface{}) time.Time {
+	if lm, ok := data.(LastModifier); ok {
+		return lm.LastModified().UTC().Truncate(time.Second)
+	}
+	return time.Time{}
+}
+
+func parseHTTPDate(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// isNotModified evaluates the If-None-Match and If-Modified-Since headers of a GET or HEAD request.
+// As stated in RFC 7232, If-Modified-Since is ignored when If-None-Match is given.
+func isNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if ifNoneMatch := r.Header.Get(HeaderNameIfNoneMatch); ifNoneMatch != "" {
+		return etag != "" && utils.MatchEtag(ifNoneMatch, etag, false)
+	}
+
+	if since, ok := parseHTTPDate(r.Header.Get(HeaderNameIfModifiedSince)); ok && !lastModified.IsZero() {
+		return !lastModified.After(since)
+	}
+	return false
+}
+
+// CheckWritePreconditions evaluates the If-Match and If-Unmodified-Since headers of a request modifying resource.
+// A request without any of these headers is rejected, to prevent lost updates.
+// It returns nil when the request can be processed.
+func CheckWritePreconditions(c *gin.Context, resource interface{}) *model.APIError {
+	ifMatch := c.GetHeader(HeaderNameIfMatch)
+	ifUnmodifiedSince := c.GetHeader(HeaderNameIfUnmodifiedSince)
+
+	switch {
+	case ifMatch != "":
+		etag, err := utils.GenerateEtag(resource)
+		if err != nil || !utils.MatchEtag(ifMatch, etag, true) {
+			return &model.ErrVersionMismatched
+		}
+	case ifUnmodifiedSince != "":
+		since, ok := parseHTTPDate(ifUnmodifiedSince)
+		lastModified := getLastModified(resource)
+		if !ok || lastModified.IsZero() || lastModified.After(since) {
+			return &model.ErrVersionMismatched
+		}
+	default:
+		return &model.ErrPreconditionRequired
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package httputils
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/denouche/go-api-skeleton/storage/dao"
+	"github.com/denouche/go-api-skeleton/storage/model"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
+)
+
+// keyPrefixDAOError prefixes the keys of the descriptions of the api errors converted from dao errors, formatted with the subject of the error
+const keyPrefixDAOError = "dao."
+
+// daoErrorsWithSubject lists the dao error types whose description is about the subject of the error
+var daoErrorsWithSubject = map[dao.Type]bool{
+	dao.ErrTypeNotFound:            true,
+	dao.ErrTypeDuplicate:           true,
+	dao.ErrTypeForeignKeyViolation: true,
+	dao.ErrTypeConflict:            true,
+}
+
+// NewAPIErrorFromError converts an error to the APIError to send to the client.
+// APIErrors are returned as is, DAOErrors are mapped depending on their type, a body read past its maximum size is a
+// request too large error, and any other error is an internal server error.
+// subject describes the entity concerned by the error ("Template", "Template to update"), it can be empty.
+func NewAPIErrorFromError(err error, subject string) model.APIError {
+	var apiErr *model.APIError
+	if errors.As(err, &apiErr) {
+		return *apiErr
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return model.ErrRequestTooLarge
+	}
+
+	var daoErr *dao.DAOError
+	if !errors.As(err, &daoErr) {
+		return model.ErrInternalServer
+	}
+
+	var result model.APIError
+	switch daoErr.Type {
+	case dao.ErrTypeNotFound:
+		result = model.ErrNotFound
+	case dao.ErrTypeDuplicate:
+		result = model.ErrAlreadyExists
+	case dao.ErrTypeForeignKeyViolation:
+		result = model.ErrReferenceNotFound
+	case dao.ErrTypeConflict:
+		result = model.ErrConflict
+	case dao.ErrTypeTimeout:
+		result = model.ErrGatewayTimeout
+	case dao.ErrTypeUnavailable:
+		result = model.ErrServiceUnavailable
+	default:
+		return model.ErrInternalServer
+	}
+	if daoErrorsWithSubject[daoErr.Type] && subject != "" {
+		result.MessageKey = keyPrefixDAOError + daoErr.Type.String()
+		result.MessageArgs = []interface{}{subject}
+		result.Description = i18n.Sprintf(i18n.DefaultLanguage(), result.MessageKey, subject, subject)
+	}
+	return result
+}
+
+// localizeAPIError translates the description and the details descriptions of the error in lang
+func localizeAPIError(lang string, e model.APIError) model.APIError {
+	if lang == "" {
+		return e
+	}
+
+	if e.MessageKey != "" {
+		e.Description = i18n.Sprintf(lang, e.MessageKey, e.Description, e.MessageArgs...)
+	} else if defaultDescription, ok := i18n.Translate(i18n.DefaultLanguage(), i18n.KeyPrefixError+e.Type); ok && defaultDescription == e.Description {
+		// only the default description of the error type is translated, not the specific messages given by the handlers
+		e.Description = i18n.Sprintf(lang, i18n.KeyPrefixError+e.Type, e.Description)
+	}
+
+	if len(e.Details) > 0 {
+		details := make([]model.FieldError, len(e.Details))
+		for i, d := range e.Details {
+			d.Description = i18n.Sprintf(lang, i18n.KeyPrefixValidation+d.Constraint, d.Description, d.Param)
+			details[i] = d
+		}
+		e.Details = details
+	}
+	return e
+}
@@ -1,16 +1,27 @@
 package httputils
 
 const (
-	HeaderNameAccept        = "accept"
-	HeaderNameAuthorization = "authorization"
-	HeaderNameCacheControl  = "cache-control"
-	HeaderNameContentType   = "content-type"
-	HeaderNameCorrelationID = "correlationID"
-	HeaderNameETag          = "ETag"
-	HeaderNameExpires       = "expires"
-	HeaderNameIfMatch       = "If-Match"
-	HeaderNameIfNoneMatch   = "If-None-Match"
-	HeaderNameLocation      = "location"
+	HeaderNameAccept             = "accept"
+	HeaderNameAcceptLanguage     = "Accept-Language"
+	HeaderNameAcceptPatch        = "Accept-Patch"
+	HeaderNameAllow              = "Allow"
+	HeaderNameAuthorization      = "authorization"
+	HeaderNameCacheControl       = "cache-control"
+	HeaderNameContentLanguage    = "Content-Language"
+	HeaderNameContentType        = "content-type"
+	HeaderNameCorrelationID      = "correlationID"
+	HeaderNameETag               = "ETag"
+	HeaderNameIdempotencyKey     = "Idempotency-Key"
+	HeaderNameIdempotentReplayed = "Idempotent-Replayed"
+	HeaderNameExpires            = "expires"
+	HeaderNameIfMatch            = "If-Match"
+	HeaderNameIfNoneMatch        = "If-None-Match"
+	HeaderNameIfModifiedSince    = "If-Modified-Since"
+	HeaderNameIfUnmodifiedSince  = "If-Unmodified-Since"
+	HeaderNameLastModified       = "Last-Modified"
+	HeaderNameLocation           = "location"
+	HeaderNameRetryAfter         = "Retry-After"
+	HeaderNameVary               = "Vary"
 
 	// cors headers
 	HeaderNameOrigin                        = "Origin"
@@ -19,9 +30,18 @@ const (
 	HeaderNameAccessControlAllowMethods     = "Access-Control-Allow-Methods"
 	HeaderNameAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
 	HeaderNameAccessControlExposeHeaders    = "access-control-expose-headers"
+	HeaderNameAccessControlMaxAge           = "Access-Control-Max-Age"
+	HeaderNameAccessControlRequestMethod    = "Access-Control-Request-Method"
+	HeaderNameAccessControlRequestHeaders   = "Access-Control-Request-Headers"
 
-	HeaderValueApplicationJSONUTF8 = "application/json; charset=UTF-8"
-	HeaderValueApplicationYAML     = "application/x-yaml"
+	HeaderValueApplicationJSON        = "application/json"
+	HeaderValueApplicationJSONUTF8    = "application/json; charset=UTF-8"
+	HeaderValueApplicationProblemJSON = "application/problem+json"
+	HeaderValueApplicationYAML        = "application/x-yaml"
+	HeaderValueTextHTMLUTF8           = "text/html; charset=UTF-8"
+
+	HeaderValueApplicationMergePatchJSON = "application/merge-patch+json"
+	HeaderValueApplicationJSONPatchJSON  = "application/json-patch+json"
 )
 
 var AllowedHeaders = []string{
@@ -31,6 +51,17 @@ var AllowedHeaders = []string{
 	HeaderNameContentType,
 	HeaderNameCorrelationID,
 	HeaderNameExpires,
+	HeaderNameIdempotencyKey,
 	HeaderNameIfMatch,
 	HeaderNameIfNoneMatch,
+	HeaderNameIfModifiedSince,
+	HeaderNameIfUnmodifiedSince,
+}
+
+var ExposedHeaders = []string{
+	HeaderNameCorrelationID,
+	HeaderNameETag,
+	HeaderNameIdempotentReplayed,
+	HeaderNameLastModified,
+	HeaderNameLocation,
 }
@@ -0,0 +1,44 @@
+package httputils
+
+import (
+	"mime"
+
+	"github.com/denouche/go-api-skeleton/storage/model"
+	jsonpatch "github.com/evanphx/json-patch"
+)
+
+// ApplyPatch applies the patch document of a PATCH request to the original JSON document.
+// The patch format, JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902), is chosen from the request content type.
+func ApplyPatch(contentType string, original, patch []byte) ([]byte, *model.APIError) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+
+	switch mediaType {
+	case HeaderValueApplicationMergePatchJSON:
+		result, err := jsonpatch.MergePatch(original, patch)
+		if err != nil {
+			return nil, &model.ErrBadRequestFormat
+		}
+		return result, nil
+	case HeaderValueApplicationJSONPatchJSON:
+		p, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, &model.ErrBadRequestFormat
+		}
+		result, err := p.Apply(original)
+		if err != nil {
+			apiErr := model.ErrPatchFailed
+			apiErr.Description = err.Error()
+			return nil, &apiErr
+		}
+		return result, nil
+	default:
+		apiErr := model.ErrUnsupportedMediaType
+		apiErr.Headers = map[string][]string{
+			HeaderNameAcceptPatch: {HeaderValueApplicationMergePatchJSON, HeaderValueApplicationJSONPatchJSON},
+		}
+		return nil, &apiErr
+	}
+}
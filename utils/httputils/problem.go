@@ -0,0 +1,92 @@
+package httputils
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ErrorFormatLegacy  = "legacy"
+	ErrorFormatProblem = "problem"
+)
+
+var (
+	errorFormat        = ErrorFormatLegacy
+	problemTypeBaseURI = "urn:problem-type:"
+)
+
+// InitErrorFormat sets the default error format, legacy or problem (RFC 7807),
+// and the base URI of the problem types
+func InitErrorFormat(format, typeBaseURI string) error {
+	if format != ErrorFormatLegacy && format != ErrorFormatProblem {
+		return fmt.Errorf("unknown error format %q, expected %s or %s", format, ErrorFormatLegacy, ErrorFormatProblem)
+	}
+	errorFormat = format
+	problemTypeBaseURI = typeBaseURI
+	return nil
+}
+
+// isProblemDetailsWanted returns true if the error must be sent as a problem details: when it is the configured
+// format, or when the client prefers it in the Accept header to the media types of the legacy errors
+func isProblemDetailsWanted(c *gin.Context) bool {
+	// the error representation depends on the Accept header, caches must take it into account
+	c.Writer.Header().Add(HeaderNameVary, http.CanonicalHeaderKey(HeaderNameAccept))
+
+	if errorFormat == ErrorFormatProblem {
+		return true
+	}
+	// the first of the media types matching a problem details or a legacy error is the preferred one
+	for _, mediaType := range AcceptedMediaTypes(c) {
+		if strings.EqualFold(mediaType, HeaderValueApplicationProblemJSON) {
+			return true
+		}
+		if strings.EqualFold(mediaType, HeaderValueApplicationJSON) || mediaType == "application/*" || mediaType == "*/*" {
+			return false
+		}
+	}
+	return false
+}
+
+// AcceptedMediaTypes returns the media types of the request Accept header, without their parameters, from the most
+// preferred one according to their q-values, the ones of the same q-value in the given order. The media types refused
+// with q=0 are not returned.
+func AcceptedMediaTypes(c *gin.Context) []string {
+	type acceptedMediaType struct {
+		mediaType string
+		quality   float64
+	}
+	var accepted []acceptedMediaType
+	for _, value := range strings.Split(c.GetHeader(HeaderNameAccept), ",") {
+		parts := strings.Split(value, ";")
+		mediaType := strings.TrimSpace(parts[0])
+		if mediaType == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range parts[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && strings.EqualFold(kv[0], "q") {
+				if q, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			accepted = append(accepted, acceptedMediaType{mediaType: mediaType, quality: quality})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+
+	mediaTypes := make([]string, 0, len(accepted))
+	for _, a := range accepted {
+		mediaTypes = append(mediaTypes, a.mediaType)
+	}
+	return mediaTypes
+}
@@ -0,0 +1,41 @@
+package httputils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIsProblemDetailsWanted(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected bool
+	}{
+		{accept: "", expected: false},
+		{accept: "application/json", expected: false},
+		{accept: "application/problem+json", expected: true},
+		{accept: "application/problem+json, application/json", expected: true},
+		{accept: "application/json, application/problem+json", expected: false},
+		{accept: "application/json;q=0.5, application/problem+json", expected: true},
+		{accept: "application/problem+json;q=0.5, application/json", expected: false},
+		{accept: "*/*, application/problem+json;q=0.1", expected: false},
+		{accept: "application/problem+json, */*;q=0.8", expected: true},
+		{accept: "application/*, application/problem+json", expected: false},
+		{accept: "text/html, application/problem+json;q=0.9, application/json;q=0.8", expected: true},
+		{accept: "application/problem+json;q=0", expected: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.accept, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set(HeaderNameAccept, tt.accept)
+
+			if wanted := isProblemDetailsWanted(c); wanted != tt.expected {
+				t.Errorf("expected %t, got %t", tt.expected, wanted)
+			}
+		})
+	}
+}
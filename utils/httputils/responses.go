@@ -6,12 +6,16 @@ import (
 
 	"github.com/denouche/go-api-skeleton/storage/model"
 	"github.com/denouche/go-api-skeleton/utils"
+	"github.com/denouche/go-api-skeleton/utils/i18n"
 	"github.com/gin-gonic/gin"
 )
 
+// JSONOK sends data with a 200 status, or a 304 Not Modified without body when the
+// validators sent by the client in If-None-Match or If-Modified-Since still match data
 func JSONOK(c *gin.Context, data interface{}) {
-	if utils.IsSameVersion(c.GetHeader(HeaderNameIfNoneMatch), data) {
-		JSON(c.Writer, http.StatusNotModified, data)
+	etag := setValidatorHeaders(c.Writer, data)
+	if isNotModified(c.Request, etag, getLastModified(data)) {
+		c.Writer.WriteHeader(http.StatusNotModified)
 		return
 	}
 	JSON(c.Writer, http.StatusOK, data)
@@ -19,31 +23,57 @@ func JSONOK(c *gin.Context, data interface{}) {
 
 func JSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set(HeaderNameContentType, HeaderValueApplicationJSONUTF8)
+	if data != nil {
+		setValidatorHeaders(w, data)
+	}
+	// headers must all be set before writing the status
 	w.WriteHeader(status)
 	if data != nil {
-		etag, err := utils.GenerateEtag(data)
-		if err == nil {
-			w.Header().Set(HeaderNameAccessControlExposeHeaders, HeaderNameETag)
-			w.Header().Set(HeaderNameETag, etag)
-		}
 		json.NewEncoder(w).Encode(data)
 	}
 }
 
-func JSONError(w http.ResponseWriter, e model.APIError) {
+// setValidatorHeaders sets the ETag and Last-Modified headers describing data, and returns the ETag
+func setValidatorHeaders(w http.ResponseWriter, data interface{}) string {
+	etag, err := utils.GenerateEtag(data)
+	if err != nil {
+		return ""
+	}
+	w.Header().Set(HeaderNameETag, etag)
+	if lastModified := getLastModified(data); !lastModified.IsZero() {
+		w.Header().Set(HeaderNameLastModified, lastModified.Format(http.TimeFormat))
+	}
+	return etag
+}
+
+// JSONError sends the error, in the legacy format or as an RFC 7807 problem details,
+// depending on the configured error format and on the request Accept header.
+// The descriptions are translated in the language negotiated from the Accept-Language header.
+func JSONError(c *gin.Context, e model.APIError) {
+	e = localizeAPIError(c.GetString(i18n.ContextKeyLanguage), e)
 	if e.Headers != nil {
 		for k, headers := range e.Headers {
 			for _, headerValue := range headers {
-				w.Header().Add(k, headerValue)
+				c.Writer.Header().Add(k, headerValue)
 			}
 		}
 	}
-	JSON(w, e.HTTPCode, e)
+
+	if !isProblemDetailsWanted(c) {
+		JSON(c.Writer, e.HTTPCode, e)
+		return
+	}
+
+	instance := c.GetString(utils.ContextKeyCorrelationID)
+	problem := e.ToProblemDetails(problemTypeBaseURI, instance)
+	c.Writer.Header().Set(HeaderNameContentType, HeaderValueApplicationProblemJSON)
+	c.Writer.WriteHeader(e.HTTPCode)
+	json.NewEncoder(c.Writer).Encode(problem)
 }
 
-func JSONErrorWithMessage(w http.ResponseWriter, e model.APIError, message string) {
+func JSONErrorWithMessage(c *gin.Context, e model.APIError, message string) {
 	e.Description = message
-	JSONError(w, e)
+	JSONError(c, e)
 }
 
 func YAML(w http.ResponseWriter, status int, data string) {
@@ -51,3 +81,16 @@ func YAML(w http.ResponseWriter, status int, data string) {
 	w.WriteHeader(status)
 	w.Write([]byte(data))
 }
+
+// RawJSON sends data, already encoded in JSON
+func RawJSON(w http.ResponseWriter, status int, data string) {
+	w.Header().Set(HeaderNameContentType, HeaderValueApplicationJSONUTF8)
+	w.WriteHeader(status)
+	w.Write([]byte(data))
+}
+
+func HTML(w http.ResponseWriter, status int, data []byte) {
+	w.Header().Set(HeaderNameContentType, HeaderValueTextHTMLUTF8)
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,199 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	ContextKeyLanguage = "language"
+
+	// KeyPrefixValidation prefixes the keys of the messages of the validator tags, e.g. validation.required
+	KeyPrefixValidation = "validation."
+	// KeyPrefixError prefixes the keys of the descriptions of the api errors, by error type, e.g. error.data_validation
+	KeyPrefixError = "error."
+
+	messagesFileExtension = ".json"
+)
+
+//go:embed messages/*.json
+var embeddedMessages embed.FS
+
+var (
+	lock            sync.RWMutex
+	defaultLanguage = "en"
+	catalog         = map[string]map[string]string{}
+	matcher         language.Matcher
+	matcherTags     []string
+)
+
+func init() {
+	entries, _ := embeddedMessages.ReadDir("messages")
+	for _, entry := range entries {
+		data, err := embeddedMessages.ReadFile("messages/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		if err := addMessages(strings.TrimSuffix(entry.Name(), messagesFileExtension), data); err != nil {
+			panic(err)
+		}
+	}
+	buildMatcher()
+}
+
+// Init sets the language used when the client does not ask for a supported one, and loads the messages
+// files of dir, if not empty. Each file is named after its language (fr.json, pt-BR.json) and contains a JSON
+// object of messages by key. Its messages are added to the built-in ones, or replace them.
+func Init(dir, defaultLang string) error {
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*"+messagesFileExtension))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			lang := strings.TrimSuffix(filepath.Base(file), messagesFileExtension)
+			if err := addMessages(lang, data); err != nil {
+				return fmt.Errorf("invalid messages file %s: %w", file, err)
+			}
+		}
+	}
+
+	lock.Lock()
+	if defaultLang != "" {
+		tag := language.Make(defaultLang)
+		base, _ := tag.Base()
+		lang := tag.String()
+		// the messages of a regional language can be the ones of its base language, like Translate falls back on them
+		if catalog[lang] == nil && catalog[base.String()] == nil {
+			lock.Unlock()
+			return fmt.Errorf("no messages for the default language %s", defaultLang)
+		}
+		defaultLanguage = lang
+	}
+	lock.Unlock()
+	buildMatcher()
+	return nil
+}
+
+func addMessages(lang string, data []byte) error {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return err
+	}
+	messages := map[string]string{}
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return err
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if catalog[tag.String()] == nil {
+		catalog[tag.String()] = map[string]string{}
+	}
+	for k, v := range messages {
+		catalog[tag.String()][k] = v
+	}
+	return nil
+}
+
+// buildMatcher builds the matcher of Accept-Language headers, with the default language as preferred one
+func buildMatcher() {
+	lock.Lock()
+	defer lock.Unlock()
+	tags := []string{defaultLanguage}
+	others := make([]string, 0, len(catalog))
+	for lang := range catalog {
+		if lang != defaultLanguage {
+			others = append(others, lang)
+		}
+	}
+	sort.Strings(others)
+	tags = append(tags, others...)
+
+	languageTags := make([]language.Tag, 0, len(tags))
+	for _, t := range tags {
+		languageTags = append(languageTags, language.Make(t))
+	}
+	matcher = language.NewMatcher(languageTags)
+	matcherTags = tags
+}
+
+// DefaultLanguage returns the language used when the client does not ask for a supported one
+func DefaultLanguage() string {
+	lock.RLock()
+	defer lock.RUnlock()
+	return defaultLanguage
+}
+
+// Languages returns the languages of the catalog
+func Languages() []string {
+	lock.RLock()
+	defer lock.RUnlock()
+	return append([]string{}, matcherTags...)
+}
+
+// Negotiate returns the supported language best matching an Accept-Language header value
+func Negotiate(acceptLanguage string) string {
+	lock.RLock()
+	defer lock.RUnlock()
+	if acceptLanguage == "" {
+		return defaultLanguage
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return defaultLanguage
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return defaultLanguage
+	}
+	return matcherTags[index]
+}
+
+// Translate returns the message of key in lang, falling back on its base language (fr for fr-CA) then on the default language
+func Translate(lang, key string) (string, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+	candidates := []string{lang}
+	if base, _ := language.Make(lang).Base(); base.String() != lang {
+		candidates = append(candidates, base.String())
+	}
+	candidates = append(candidates, defaultLanguage)
+	for _, l := range candidates {
+		if message, ok := catalog[l][key]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// Sprintf formats the message of key in lang with args, or returns fallback when the key is unknown.
+// Like validator tags params, the args not used by the message are ignored.
+func Sprintf(lang, key, fallback string, args ...interface{}) string {
+	message, ok := Translate(lang, key)
+	if !ok {
+		return fallback
+	}
+	return TruncatingSprintf(message, args...)
+}
+
+// TruncatingSprintf is used as fmt.Sprintf but allow to truncate the additional parameters given when there is more parameters than %v in str
+func TruncatingSprintf(str string, args ...interface{}) string {
+	n := strings.Count(str, "%v") + strings.Count(str, "%s")
+	if n > len(args) {
+		n = len(args)
+	}
+	return fmt.Sprintf(str, args[:n]...)
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logSampler is the sampler of all the loggers, nil when the sampling is disabled
+var logSampler *sampler
+
+// sampler counts the logs of each message by level during a tick, to drop the noisy ones
+type sampler struct {
+	initial    int
+	thereafter int
+	tick       time.Duration
+
+	lock      sync.Mutex
+	tickStart time.Time
+	counts    map[samplerKey]int
+}
+
+type samplerKey struct {
+	level   logrus.Level
+	message string
+}
+
+func newSampler(initial, thereafter int, tick time.Duration) *sampler {
+	return &sampler{
+		initial:    initial,
+		thereafter: thereafter,
+		tick:       tick,
+		counts:     map[samplerKey]int{},
+	}
+}
+
+// keep returns true when the entry is to be logged: the initial first ones of its message during the tick, then one
+// every thereafter ones
+func (s *sampler) keep(entry *logrus.Entry) bool {
+	if entry.Level <= logrus.ErrorLevel {
+		return true
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	if now.Sub(s.tickStart) >= s.tick {
+		// the counts of the previous tick are dropped, so that the messages logged once do not accumulate
+		s.tickStart = now
+		s.counts = map[samplerKey]int{}
+	}
+	key := samplerKey{level: entry.Level, message: entry.Message}
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.initial {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
+
+// samplingFormatter formats the entries kept by the sampler, the dropped ones are formatted as nothing
+type samplingFormatter struct {
+	formatter logrus.Formatter
+	sampler   *sampler
+}
+
+func newSamplingFormatter(formatter logrus.Formatter, s *sampler) logrus.Formatter {
+	if s == nil {
+		return formatter
+	}
+	return &samplingFormatter{formatter: formatter, sampler: s}
+}
+
+func (f *samplingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if !f.sampler.keep(entry) {
+		return nil, nil
+	}
+	return f.formatter.Format(entry)
+}
@@ -1,8 +1,13 @@
 package utils
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -12,15 +17,63 @@ const (
 	LogFormatText = "text"
 	LogFormatJSON = "json"
 
-	ContextKeyLogger = "logger"
+	ContextKeyLogger        = "logger"
+	ContextKeyCorrelationID = "correlationID"
+
+	// LoggerComponentRoot is the logger of everything not logged by a component, whose level is inherited by the
+	// components without a level of their own
+	LoggerComponentRoot       = "root"
+	LoggerComponentHTTP       = "http"
+	LoggerComponentDAO        = "dao"
+	LoggerComponentValidators = "validators"
+
+	logFieldComponent = "component"
 )
 
+// LoggerLevel is the level of a component logger
+type LoggerLevel struct {
+	Component string `json:"component"`
+	Level     string `json:"level"`
+	// Inherited is true when the component has no level of its own and follows the root logger
+	Inherited bool `json:"inherited"`
+	// RevertAt is the time when a temporary level is reverted to the previous one
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+}
+
+// componentLogger is the logger of a component, sharing the format and output of the root logger
+type componentLogger struct {
+	logger *logrus.Logger
+	// level is the level of the component, nil when inherited from the root logger
+	level *logrus.Level
+	// revert restores the previous level of a temporary level, at revertAt
+	revert   *time.Timer
+	revertAt time.Time
+	previous *logrus.Level
+}
+
 var (
 	logLevel                   = logrus.DebugLevel
 	logFormat logrus.Formatter = &logrus.TextFormatter{}
-	logOut    io.Writer
+	logOut    io.Writer        = os.Stdout
+
+	loggersLock sync.Mutex
+	rootLogger  = newComponentLogger()
+	loggers     = map[string]*componentLogger{
+		LoggerComponentRoot:       rootLogger,
+		LoggerComponentHTTP:       newComponentLogger(),
+		LoggerComponentDAO:        newComponentLogger(),
+		LoggerComponentValidators: newComponentLogger(),
+	}
 )
 
+func newComponentLogger() *componentLogger {
+	logger := logrus.New()
+	logger.Formatter = logFormat
+	logger.Level = logLevel
+	logger.Out = logOut
+	return &componentLogger{logger: logger}
+}
+
 func InitLogger(ll, lf string) {
 	logLevel = parseLogrusLevel(ll)
 	logrus.SetLevel(logLevel)
@@ -30,6 +83,30 @@ func InitLogger(ll, lf string) {
 
 	logOut = os.Stdout
 	logrus.SetOutput(logOut)
+
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+	for _, l := range loggers {
+		l.logger.SetFormatter(newSamplingFormatter(logFormat, logSampler))
+		l.logger.SetOutput(logOut)
+	}
+	level := logLevel
+	rootLogger.level = &level
+	applyLevels()
+}
+
+// InitLogSampling limits the logging of each message, by level, to the initial first ones each second then one every
+// thereafter ones, 0 to drop all of them. A 0 initial disables the sampling. The errors are never sampled.
+func InitLogSampling(initial, thereafter int) {
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+	logSampler = nil
+	if initial > 0 {
+		logSampler = newSampler(initial, thereafter, time.Second)
+	}
+	for _, l := range loggers {
+		l.logger.SetFormatter(newSamplingFormatter(logFormat, logSampler))
+	}
 }
 
 func GetLoggerFromCtx(c *gin.Context) *logrus.Entry {
@@ -44,12 +121,149 @@ func GetLoggerFromCtx(c *gin.Context) *logrus.Entry {
 	return logrus.NewEntry(GetLogger())
 }
 
+// GetLogger returns the root logger, shared by the whole api
 func GetLogger() *logrus.Logger {
-	logger := logrus.New()
-	logger.Formatter = logFormat
-	logger.Level = logLevel
-	logger.Out = logOut
-	return logger
+	return rootLogger.logger
+}
+
+// GetComponentLogger returns the logger of a component, logging at its own level with a component field
+func GetComponentLogger(component string) *logrus.Entry {
+	loggersLock.Lock()
+	l, ok := loggers[component]
+	loggersLock.Unlock()
+	if !ok || component == LoggerComponentRoot {
+		return logrus.NewEntry(GetLogger())
+	}
+	return l.logger.WithField(logFieldComponent, component)
+}
+
+// GetLogLevels returns the levels of the root and components loggers
+func GetLogLevels() []LoggerLevel {
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+	result := make([]LoggerLevel, 0, len(loggers))
+	for component, l := range loggers {
+		level := LoggerLevel{
+			Component: component,
+			Level:     l.logger.GetLevel().String(),
+			Inherited: l.level == nil,
+		}
+		if l.revert != nil {
+			revertAt := l.revertAt
+			level.RevertAt = &revertAt
+		}
+		result = append(result, level)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Component < result[j].Component
+	})
+	return result
+}
+
+// SetLogLevel sets the level of a component logger, LoggerComponentRoot for the root one. When duration is positive
+// the previous level is restored after it.
+func SetLogLevel(component, level string, duration time.Duration) error {
+	l, ok := loggers[component]
+	if !ok {
+		return fmt.Errorf("unknown logger component %s", component)
+	}
+	parsedLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+	previous := l.level
+	if l.revert != nil {
+		// a temporary level replacing another one reverts to the level set before both of them
+		previous = l.previous
+		l.revert.Stop()
+		l.revert = nil
+	}
+	l.level = &parsedLevel
+	if duration > 0 {
+		l.revertAt = time.Now().Add(duration)
+		var revert *time.Timer
+		revert = time.AfterFunc(duration, func() {
+			loggersLock.Lock()
+			defer loggersLock.Unlock()
+			if l.revert != revert {
+				// replaced by another level meanwhile
+				return
+			}
+			l.level = previous
+			l.revert = nil
+			applyLevels()
+			GetLogger().WithField(logFieldComponent, component).Warn("temporary log level reverted")
+		})
+		l.revert = revert
+		l.previous = previous
+	}
+	applyLevels()
+	return nil
+}
+
+// ResetLogLevels sets the level of the root logger and the levels of the components, the other components inheriting
+// the root level. The temporary levels are canceled.
+func ResetLogLevels(level string, componentLevels map[string]string) error {
+	parsedLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	parsedLevels := map[string]logrus.Level{LoggerComponentRoot: parsedLevel}
+	for component, componentLevel := range componentLevels {
+		if _, ok := loggers[component]; !ok {
+			return fmt.Errorf("unknown logger component %s", component)
+		}
+		parsedLevels[component], err = logrus.ParseLevel(componentLevel)
+		if err != nil {
+			return err
+		}
+	}
+
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+	for component, l := range loggers {
+		if l.revert != nil {
+			l.revert.Stop()
+			l.revert = nil
+		}
+		l.level = nil
+		if componentLevel, ok := parsedLevels[component]; ok {
+			l.level = &componentLevel
+		}
+	}
+	applyLevels()
+	return nil
+}
+
+// ParseLogComponentLevels parses the levels of the components given as component=level
+func ParseLogComponentLevels(values []string) (map[string]string, error) {
+	result := map[string]string{}
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid component log level %s, expected component=level", v)
+		}
+		result[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return result, nil
+}
+
+// applyLevels sets the level of each logger, the inherited ones following the root one. loggersLock must be held.
+func applyLevels() {
+	rootLevel := logLevel
+	if rootLogger.level != nil {
+		rootLevel = *rootLogger.level
+	}
+	for _, l := range loggers {
+		level := rootLevel
+		if l.level != nil {
+			level = *l.level
+		}
+		l.logger.SetLevel(level)
+	}
 }
 
 func parseLogrusLevel(logLevelStr string) logrus.Level {
@@ -0,0 +1,361 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	annotationPath   = "@openapi:path"
+	annotationSchema = "@openapi:schema"
+
+	openAPIVersion = "3.0.0"
+
+	// FileNameYAML and FileNameJSON are the names of the generated documents in the output directory
+	FileNameYAML = "openapi.yaml"
+	FileNameJSON = "openapi.json"
+
+	generatedHeader = "# Code generated by go-api-skeleton openapi generate; DO NOT EDIT.\n"
+)
+
+// topLevelKeysOrder is the order of the keys at the top of the YAML document, the other ones are sorted after them
+var topLevelKeysOrder = []string{"openapi", "info", "servers", "tags", "security", "paths", "components"}
+
+type Config struct {
+	// Dir is the root directory of the sources to parse
+	Dir string
+	// Exclude is the names of the directories not parsed
+	Exclude []string
+	// InfoFile is the file merged in the document, giving its info, servers, tags...
+	InfoFile string
+	// OutputDir is the directory where the openapi.yaml and openapi.json files are written
+	OutputDir string
+}
+
+type annotatedType struct {
+	name        string
+	description string
+	expr        ast.Expr
+}
+
+type generator struct {
+	paths map[string]interface{}
+	// structs are all the struct types of the sources by name, to inline the embedded and not annotated ones
+	structs map[string]*ast.StructType
+	types   []annotatedType
+	// annotated are the names of the types given as schemas, referenced instead of inlined
+	annotated map[string]bool
+}
+
+// Generate builds the OpenAPI document from the @openapi:path and @openapi:schema annotations of the sources,
+// merged in the info file, and writes it as YAML and JSON in the output directory.
+func Generate(config Config) error {
+	g := &generator{
+		paths:     map[string]interface{}{},
+		structs:   map[string]*ast.StructType{},
+		annotated: map[string]bool{},
+	}
+
+	err := g.parseDir(config.Dir, config.Exclude)
+	if err != nil {
+		return err
+	}
+
+	doc := map[string]interface{}{"openapi": openAPIVersion}
+	if config.InfoFile != "" {
+		info, err := readYAMLFile(config.InfoFile)
+		if err != nil {
+			return err
+		}
+		mergeMaps(doc, info)
+	}
+	mergeMaps(doc, map[string]interface{}{
+		"paths": g.paths,
+		"components": map[string]interface{}{
+			"schemas": g.schemas(),
+		},
+	})
+
+	yamlDoc, err := marshalYAML(doc)
+	if err != nil {
+		return err
+	}
+	jsonDoc, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// the api must not be built with a document the validation middleware would refuse
+	loader := openapi3.NewLoader()
+	parsed, err := loader.LoadFromData(jsonDoc)
+	if err != nil {
+		return fmt.Errorf("unable to parse the generated document: %w", err)
+	}
+	err = parsed.Validate(loader.Context)
+	if err != nil {
+		return fmt.Errorf("the generated document is not valid: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(config.OutputDir, FileNameYAML), yamlDoc, 0644)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(config.OutputDir, FileNameJSON), append(jsonDoc, '\n'), 0644)
+}
+
+// parseDir parses the go files of dir and its sub directories, except the excluded and hidden ones
+func (g *generator) parseDir(dir string, exclude []string) error {
+	excluded := map[string]bool{}
+	for _, e := range exclude {
+		excluded[e] = true
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (excluded[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// the types are collected first, the schemas referencing the ones declared in the next files
+	for _, f := range files {
+		g.collectTypes(f)
+	}
+	for _, f := range files {
+		for _, cg := range f.Comments {
+			err = g.parsePathAnnotation(cg, fset.Position(cg.Pos()).String())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *generator) collectTypes(f *ast.File) {
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if st, ok := typeSpec.Type.(*ast.StructType); ok {
+				g.structs[typeSpec.Name.Name] = st
+			}
+
+			doc := typeSpec.Doc
+			if doc == nil && len(genDecl.Specs) == 1 {
+				doc = genDecl.Doc
+			}
+			lines := commentLines(doc)
+			for i, line := range lines {
+				if strings.TrimSpace(line) == annotationSchema {
+					g.annotated[typeSpec.Name.Name] = true
+					g.types = append(g.types, annotatedType{
+						name:        typeSpec.Name.Name,
+						description: strings.TrimSpace(strings.Join(lines[:i], "\n")),
+						expr:        typeSpec.Type,
+					})
+					break
+				}
+			}
+		}
+	}
+}
+
+// parsePathAnnotation adds the operations described in YAML after the @openapi:path line of the comment
+func (g *generator) parsePathAnnotation(cg *ast.CommentGroup, position string) error {
+	lines := commentLines(cg)
+	for i, line := range lines {
+		if strings.TrimSpace(line) != annotationPath {
+			continue
+		}
+
+		// the annotations are indented with tabs, which YAML does not allow
+		fragment := strings.ReplaceAll(strings.Join(lines[i+1:], "\n"), "\t", "  ")
+		var paths interface{}
+		err := yaml.Unmarshal([]byte(fragment), &paths)
+		if err != nil {
+			return fmt.Errorf("%s: invalid %s annotation: %w", position, annotationPath, err)
+		}
+		pathsMap, ok := normalize(paths).(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: invalid %s annotation: the paths must be a map", position, annotationPath)
+		}
+
+		for path, operations := range pathsMap {
+			operationsMap, ok := operations.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s: invalid %s annotation: the operations of %s must be a map", position, annotationPath, path)
+			}
+			existing, _ := g.paths[path].(map[string]interface{})
+			if existing == nil {
+				existing = map[string]interface{}{}
+				g.paths[path] = existing
+			}
+			for method, operation := range operationsMap {
+				if _, ok := existing[method]; ok {
+					return fmt.Errorf("%s: the operation %s %s is already described", position, method, path)
+				}
+				existing[method] = operation
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+func (g *generator) schemas() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	for _, t := range g.types {
+		schema := g.schemaOf(t.expr)
+		if t.description != "" {
+			schema["description"] = t.description
+		}
+		schemas[t.name] = schema
+	}
+	return schemas
+}
+
+// commentLines returns the lines of the line comments, without the leading // and the space following it
+func commentLines(cg *ast.CommentGroup) []string {
+	if cg == nil {
+		return nil
+	}
+	var lines []string
+	for _, c := range cg.List {
+		if !strings.HasPrefix(c.Text, "//") {
+			continue
+		}
+		line := strings.TrimPrefix(c.Text, "//")
+		lines = append(lines, strings.TrimPrefix(line, " "))
+	}
+	return lines
+}
+
+func readYAMLFile(file string) (map[string]interface{}, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var content interface{}
+	err = yaml.Unmarshal(b, &content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file %s: %w", file, err)
+	}
+	m, ok := normalize(content).(map[string]interface{})
+	if !ok && content != nil {
+		return nil, fmt.Errorf("invalid file %s: the content must be a map", file)
+	}
+	return m, nil
+}
+
+// normalize converts the maps decoded from YAML to maps of strings, as the status codes are decoded as integers
+func normalize(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, e := range value {
+			value[k] = normalize(e)
+		}
+		return value
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, e := range value {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range value {
+			value[i] = normalize(e)
+		}
+		return value
+	}
+	return v
+}
+
+// mergeMaps adds the content of src to dst, the values of src replacing the ones of dst unless both are maps
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// marshalYAML writes the keys of the document in the usual order of the OpenAPI documents
+func marshalYAML(doc map[string]interface{}) ([]byte, error) {
+	keys := make([]string, 0, len(doc))
+	for _, k := range topLevelKeysOrder {
+		if _, ok := doc[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	var others []string
+	for k := range doc {
+		if !contains(topLevelKeysOrder, k) {
+			others = append(others, k)
+		}
+	}
+	sort.Strings(others)
+	keys = append(keys, others...)
+
+	buf := bytes.NewBufferString(generatedHeader)
+	for _, k := range keys {
+		encoder := yaml.NewEncoder(buf)
+		encoder.SetIndent(2)
+		err := encoder.Encode(map[string]interface{}{k: doc[k]})
+		if err != nil {
+			return nil, err
+		}
+		err = encoder.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
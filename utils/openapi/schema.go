@@ -0,0 +1,301 @@
+package openapi
+
+import (
+	"go/ast"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// validatePatterns are the patterns equivalent to the validator tags checking the characters of a string
+var validatePatterns = map[string]string{
+	"alpha":       "^[a-zA-Z]+$",
+	"alphanum":    "^[a-zA-Z0-9]+$",
+	"numeric":     "^[-+]?[0-9]+(?:\\.[0-9]+)?$",
+	"number":      "^[0-9]+$",
+	"hexadecimal": "^(0[xX])?[0-9a-fA-F]+$",
+}
+
+// validateFormats are the formats equivalent to the validator tags
+var validateFormats = map[string]string{
+	"email": "email",
+	"url":   "uri",
+	"uri":   "uri",
+	"uuid":  "uuid",
+	"ipv4":  "ipv4",
+	"ipv6":  "ipv6",
+}
+
+// schemaOf returns the schema of a type expression. The annotated types are referenced, the other structs are inlined.
+func (g *generator) schemaOf(expr ast.Expr) map[string]interface{} {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return map[string]interface{}{"type": "string"}
+		case "bool":
+			return map[string]interface{}{"type": "boolean"}
+		case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "byte", "rune":
+			return map[string]interface{}{"type": "integer"}
+		case "int64", "uint64":
+			return map[string]interface{}{"type": "integer", "format": "int64"}
+		case "float32":
+			return map[string]interface{}{"type": "number", "format": "float"}
+		case "float64":
+			return map[string]interface{}{"type": "number", "format": "double"}
+		}
+		return g.namedSchema(t.Name)
+	case *ast.SelectorExpr:
+		switch pkg, _ := t.X.(*ast.Ident); {
+		case pkg != nil && pkg.Name == "time" && t.Sel.Name == "Time":
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		case pkg != nil && pkg.Name == "json" && t.Sel.Name == "RawMessage":
+			return map[string]interface{}{}
+		}
+		return g.namedSchema(t.Sel.Name)
+	case *ast.StarExpr:
+		schema := g.schemaOf(t.X)
+		// the siblings of a reference are ignored
+		if _, ok := schema["$ref"]; !ok {
+			schema["nullable"] = true
+		}
+		return schema
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schemaOf(t.Elt)}
+	case *ast.MapType:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaOf(t.Value)}
+	case *ast.StructType:
+		return g.structSchema(t)
+	}
+	// interfaces, and the types not handled accept any value
+	return map[string]interface{}{}
+}
+
+func (g *generator) namedSchema(name string) map[string]interface{} {
+	if g.annotated[name] {
+		return map[string]interface{}{"$ref": schemaRefPrefix + name}
+	}
+	if st, ok := g.structs[name]; ok {
+		return g.structSchema(st)
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema returns the schema of the JSON representation of a struct, with the constraints of its validate tags
+func (g *generator) structSchema(st *ast.StructType) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	for _, field := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+		}
+		jsonName := strings.SplitN(tag.Get("json"), ",", 2)[0]
+		if jsonName == "-" {
+			continue
+		}
+
+		if len(field.Names) == 0 && jsonName == "" {
+			// the fields of the embedded structs are promoted in the JSON representation
+			embedded := field.Type
+			if star, ok := embedded.(*ast.StarExpr); ok {
+				embedded = star.X
+			}
+			if ident, ok := embedded.(*ast.Ident); ok {
+				if embeddedStruct, ok := g.structs[ident.Name]; ok {
+					embeddedSchema := g.structSchema(embeddedStruct)
+					if embeddedProperties, ok := embeddedSchema["properties"].(map[string]interface{}); ok {
+						for k, v := range embeddedProperties {
+							properties[k] = v
+						}
+					}
+					if embeddedRequired, ok := embeddedSchema["required"].([]string); ok {
+						required = append(required, embeddedRequired...)
+					}
+				}
+			}
+			continue
+		}
+
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent("")}
+		}
+		for _, name := range names {
+			if name.Name != "" && !ast.IsExported(name.Name) {
+				continue
+			}
+			propertyName := jsonName
+			if propertyName == "" {
+				propertyName = name.Name
+			}
+
+			schema := g.schemaOf(field.Type)
+			if applyValidateTag(schema, tag.Get("validate")) {
+				required = append(required, propertyName)
+			}
+			properties[propertyName] = schema
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// applyValidateTag adds to the schema the constraints of a validate tag, and returns whether the field is required.
+// The constraints following dive apply to the items of the array. The fields tagged omitempty are only constrained
+// by their values list, to which their zero value is added.
+func applyValidateTag(schema map[string]interface{}, validateTag string) bool {
+	if validateTag == "" {
+		return false
+	}
+	tags := strings.Split(validateTag, ",")
+	var itemsTags []string
+	for i, t := range tags {
+		if t == "dive" {
+			tags, itemsTags = tags[:i], tags[i+1:]
+			break
+		}
+	}
+
+	omitEmpty := false
+	required := false
+	for _, t := range tags {
+		switch t {
+		case "omitempty":
+			omitEmpty = true
+		case "required":
+			required = true
+		}
+	}
+
+	if _, ok := schema["$ref"]; ok {
+		// the siblings of a reference are ignored
+		return required
+	}
+
+	for _, t := range tags {
+		name, param := t, ""
+		if i := strings.Index(t, "="); i >= 0 {
+			name, param = t[:i], t[i+1:]
+		}
+		if omitEmpty && name != "oneof" {
+			continue
+		}
+		applyConstraint(schema, name, param, omitEmpty)
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok && len(itemsTags) > 0 {
+		applyValidateTag(items, strings.Join(itemsTags, ","))
+	}
+	return required
+}
+
+func applyConstraint(schema map[string]interface{}, name, param string, omitEmpty bool) {
+	schemaType, _ := schema["type"].(string)
+
+	switch name {
+	case "min", "max", "len":
+		var keywords []string
+		switch schemaType {
+		case "string":
+			keywords = []string{"minLength", "maxLength"}
+		case "array":
+			keywords = []string{"minItems", "maxItems"}
+		case "object":
+			keywords = []string{"minProperties", "maxProperties"}
+		case "integer", "number":
+			keywords = []string{"minimum", "maximum"}
+		default:
+			return
+		}
+		value := parseNumber(param)
+		if value == nil {
+			return
+		}
+		if name != "max" {
+			schema[keywords[0]] = value
+		}
+		if name != "min" {
+			schema[keywords[1]] = value
+		}
+	case "gt", "gte", "lt", "lte":
+		value := parseNumber(param)
+		if value == nil || (schemaType != "integer" && schemaType != "number") {
+			return
+		}
+		if strings.HasPrefix(name, "g") {
+			schema["minimum"] = value
+			schema["exclusiveMinimum"] = name == "gt"
+		} else {
+			schema["maximum"] = value
+			schema["exclusiveMaximum"] = name == "lt"
+		}
+	case "oneof":
+		var values []interface{}
+		if omitEmpty {
+			values = append(values, zeroValue(schemaType))
+		}
+		for _, v := range strings.Fields(param) {
+			if schemaType == "integer" || schemaType == "number" {
+				if n := parseNumber(v); n != nil {
+					values = append(values, n)
+				}
+				continue
+			}
+			values = append(values, v)
+		}
+		schema["enum"] = values
+	case "startswith":
+		schema["pattern"] = "^" + regexp.QuoteMeta(param)
+	case "endswith":
+		schema["pattern"] = regexp.QuoteMeta(param) + "$"
+	case "contains":
+		schema["pattern"] = regexp.QuoteMeta(param)
+	case "unique":
+		// with a param, the unique validator checks the database
+		if param == "" && schemaType == "array" {
+			schema["uniqueItems"] = true
+		}
+	default:
+		if pattern, ok := validatePatterns[name]; ok && schemaType == "string" {
+			schema["pattern"] = pattern
+		} else if format, ok := validateFormats[name]; ok && schemaType == "string" {
+			schema["format"] = format
+		}
+	}
+}
+
+// parseNumber returns the param as an integer when it is one, as a float otherwise, or nil when it is not a number
+func parseNumber(param string) interface{} {
+	if i, err := strconv.ParseInt(param, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(param, 64); err == nil {
+		return f
+	}
+	return nil
+}
+
+func zeroValue(schemaType string) interface{} {
+	switch schemaType {
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+// Package tracing sets up the OpenTelemetry tracing of the api, and the W3C trace context propagation
+// (traceparent and tracestate headers) of the incoming and outgoing requests.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+
+	tracerName = "github.com/denouche/go-api-skeleton"
+)
+
+type Config struct {
+	// Exporter is none, stdout or otlp. With none, the trace context of the requests is propagated but no span
+	// is recorded.
+	Exporter string
+	// OTLPEndpoint is the URL of the OTLP/HTTP collector, e.g. http://localhost:4318. When empty, the
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variable or the default endpoint of the exporter is used.
+	OTLPEndpoint   string
+	SampleRatio    float64
+	ServiceName    string
+	ServiceVersion string
+}
+
+func init() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Init sets the global tracer provider, exporting the spans to the exporter of the config.
+// The returned func flushes the spans not yet exported and stops the exporter, it must be called on shutdown.
+func Init(config Config) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if config.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(config.OTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(context.Background(), options...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, expected %s, %s or %s", config.Exporter, ExporterNone,
+			ExporterStdout, ExporterOTLP)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := newTracerProvider(config, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// InitWithExporter sets the global tracer provider, exporting each span to exporter as soon as it ends.
+// It is meant for the tests, with an in-memory exporter like tracetest.NewInMemoryExporter.
+func InitWithExporter(config Config, exporter sdktrace.SpanExporter) func(context.Context) error {
+	provider := newTracerProvider(config, sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown
+}
+
+func newTracerProvider(config Config, exporter sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		exporter,
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(config.ServiceName),
+			semconv.ServiceVersion(config.ServiceVersion),
+		)),
+	)
+}
+
+// Tracer returns the tracer of the api
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Extract returns ctx with the trace context of the headers of an incoming request, if any
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Inject adds the trace context of ctx to the headers of an outgoing request
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// TraceID returns the trace ID of the span of ctx, or a new random one when ctx has no valid span,
+// to identify the requests the same way whether they are traced or not
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.HasTraceID() {
+		return spanContext.TraceID().String()
+	}
+	var traceID trace.TraceID
+	_, _ = rand.Read(traceID[:])
+	return traceID.String()
+}
+
+// IsTraced returns true when ctx has a valid span, recorded or given by the trace context of the request
+func IsTraced(ctx context.Context) bool {
+	return trace.SpanContextFromContext(ctx).IsValid()
+}